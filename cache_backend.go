@@ -0,0 +1,387 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"github.com/go-redis/redis/v8"
+	"sync"
+	"time"
+)
+
+// ErrNotConfigured Setup veya Use çağrılmadan cache kullanıldığında
+var ErrNotConfigured = errors.New("cache: backend ayarlanmamış")
+
+// ErrScriptNotSupported lua script'leri sadece redis backend'i çalıştırabilir
+var ErrScriptNotSupported = errors.New("cache: backend script desteklemiyor")
+
+// Backend cache'in depolama katmanı. Get key yoksa *NotFoundError döner.
+// invalidation dinleyicileri backend'e göre tutulduğu için implementasyonlar pointer olmalı
+type Backend interface {
+	Ping(ctx context.Context) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// SetNX key yoksa yazar. key zaten varsa false döner
+	SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error)
+	Delete(ctx context.Context, keys ...string) error
+	Exists(ctx context.Context, key string) (bool, error)
+	// TTL key'in kalan süresi. key yoksa veya süresi yoksa 0
+	TTL(ctx context.Context, key string) (time.Duration, error)
+	// CompareAndDelete key'in değeri value ise siler
+	CompareAndDelete(ctx context.Context, key string, value []byte) (bool, error)
+	Publish(ctx context.Context, channel, message string) error
+	// Subscribe dönen kanal ctx bitince kapanır
+	Subscribe(ctx context.Context, channel string) (<-chan string, error)
+}
+
+var (
+	backendMu sync.RWMutex
+	backend   Backend
+)
+
+// Use uygulamanın default backend'ini ayarlar. process içi katmanlar varsa invalidation'lar
+// artık yeni backend'den dinlenir
+func Use(b Backend) {
+	backendMu.Lock()
+	old := backend
+	backend = b
+	backendMu.Unlock()
+	if hasLocalTiers() {
+		startListening(b)
+	}
+	if old != nil && old != b {
+		stopListening(old)
+	}
+}
+
+// Default Setup veya Use ile ayarlanan backend. ayarlanmadıysa her çağrıda ErrNotConfigured dönen backend
+func Default() Backend {
+	backendMu.RLock()
+	defer backendMu.RUnlock()
+	if backend == nil {
+		return unconfigured{}
+	}
+	return backend
+}
+
+// RedisBackend redis üzerinde Backend
+type RedisBackend struct {
+	client *redis.Client
+}
+
+func NewRedisBackend(client *redis.Client) *RedisBackend {
+	return &RedisBackend{client: client}
+}
+
+// Client script ve pool istatistikleri gibi redis'e özel işler için
+func (b *RedisBackend) Client() *redis.Client {
+	return b.client
+}
+
+func (b *RedisBackend) Ping(ctx context.Context) error {
+	return b.client.Ping(ctx).Err()
+}
+
+func (b *RedisBackend) Get(ctx context.Context, key string) ([]byte, error) {
+	data, err := b.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, &NotFoundError{Key: key}
+	}
+	return data, err
+}
+
+// GetWithTTL değeri ve kalan süresini tek round-trip'te okur
+func (b *RedisBackend) GetWithTTL(ctx context.Context, key string) ([]byte, time.Duration, error) {
+	var get *redis.StringCmd
+	var pttl *redis.DurationCmd
+	_, err := b.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		get = p.Get(ctx, key)
+		pttl = p.PTTL(ctx, key)
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, 0, err
+	}
+	data, err := get.Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, 0, &NotFoundError{Key: key}
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	ttl := pttl.Val()
+	if ttl < 0 {
+		ttl = 0
+	}
+	return data, ttl, nil
+}
+
+func (b *RedisBackend) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return b.client.Set(ctx, key, value, ttl).Err()
+}
+
+func (b *RedisBackend) SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	return b.client.SetNX(ctx, key, value, ttl).Result()
+}
+
+func (b *RedisBackend) Delete(ctx context.Context, keys ...string) error {
+	return b.client.Del(ctx, keys...).Err()
+}
+
+func (b *RedisBackend) Exists(ctx context.Context, key string) (bool, error) {
+	n, err := b.client.Exists(ctx, key).Result()
+	return n > 0, err
+}
+
+func (b *RedisBackend) TTL(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := b.client.PTTL(ctx, key).Result()
+	if err != nil || ttl < 0 {
+		return 0, err
+	}
+	return ttl, nil
+}
+
+// compareAndDeleteScript key'i sadece değeri tutuyorsa siler
+var compareAndDeleteScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+func (b *RedisBackend) CompareAndDelete(ctx context.Context, key string, value []byte) (bool, error) {
+	n, err := compareAndDeleteScript.Run(ctx, b.client, []string{key}, value).Int()
+	return n == 1, err
+}
+
+func (b *RedisBackend) Publish(ctx context.Context, channel, message string) error {
+	return b.client.Publish(ctx, channel, message).Err()
+}
+
+func (b *RedisBackend) Subscribe(ctx context.Context, channel string) (<-chan string, error) {
+	sub := b.client.Subscribe(ctx, channel)
+	out := make(chan string, 64)
+	go func() {
+		defer close(out)
+		defer sub.Close()
+		// Channel bağlantı koparsa kendisi yeniden bağlanır
+		ch := sub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-ch:
+				if !ok {
+					return
+				}
+				select {
+				case out <- msg.Payload:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out, nil
+}
+
+// MemoryBackend process içi Backend. redis olmadan geliştirme ve testler için,
+// replikalar arasında paylaşılmaz
+type MemoryBackend struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+	subs    map[string][]chan string
+	// writes süresi dolanları temizlemek için yazma sayacı
+	writes int
+}
+
+type memoryEntry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+func (e memoryEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{entries: map[string]memoryEntry{}, subs: map[string][]chan string{}}
+}
+
+func (b *MemoryBackend) Ping(ctx context.Context) error {
+	return nil
+}
+
+// lookup süresi dolmuş key'i siler. mu tutulurken çağrılmalı
+func (b *MemoryBackend) lookup(key string) (memoryEntry, bool) {
+	e, ok := b.entries[key]
+	if !ok {
+		return e, false
+	}
+	if e.expired(time.Now()) {
+		delete(b.entries, key)
+		return e, false
+	}
+	return e, true
+}
+
+func (b *MemoryBackend) Get(ctx context.Context, key string) ([]byte, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	e, ok := b.lookup(key)
+	if !ok {
+		return nil, &NotFoundError{Key: key}
+	}
+	return append([]byte(nil), e.value...), nil
+}
+
+func (b *MemoryBackend) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.set(key, value, ttl)
+	return nil
+}
+
+func (b *MemoryBackend) set(key string, value []byte, ttl time.Duration) {
+	e := memoryEntry{value: append([]byte(nil), value...)}
+	if ttl > 0 {
+		e.expiresAt = time.Now().Add(ttl)
+	}
+	b.entries[key] = e
+	// her 1024 yazmada bir süresi dolanları temizle ki hiç okunmayan key'ler birikmesin
+	b.writes++
+	if b.writes%1024 == 0 {
+		now := time.Now()
+		for k, e := range b.entries {
+			if e.expired(now) {
+				delete(b.entries, k)
+			}
+		}
+	}
+}
+
+func (b *MemoryBackend) SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.lookup(key); ok {
+		return false, nil
+	}
+	b.set(key, value, ttl)
+	return true, nil
+}
+
+func (b *MemoryBackend) Delete(ctx context.Context, keys ...string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, k := range keys {
+		delete(b.entries, k)
+	}
+	return nil
+}
+
+func (b *MemoryBackend) Exists(ctx context.Context, key string) (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	_, ok := b.lookup(key)
+	return ok, nil
+}
+
+func (b *MemoryBackend) TTL(ctx context.Context, key string) (time.Duration, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	e, ok := b.lookup(key)
+	if !ok || e.expiresAt.IsZero() {
+		return 0, nil
+	}
+	return time.Until(e.expiresAt), nil
+}
+
+func (b *MemoryBackend) CompareAndDelete(ctx context.Context, key string, value []byte) (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	e, ok := b.lookup(key)
+	if !ok || string(e.value) != string(value) {
+		return false, nil
+	}
+	delete(b.entries, key)
+	return true, nil
+}
+
+// Publish dolu olan abonelere mesaj atlanır, redis pub/sub gibi teslim garantisi yok
+func (b *MemoryBackend) Publish(ctx context.Context, channel, message string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, ch := range b.subs[channel] {
+		select {
+		case ch <- message:
+		default:
+		}
+	}
+	return nil
+}
+
+func (b *MemoryBackend) Subscribe(ctx context.Context, channel string) (<-chan string, error) {
+	ch := make(chan string, 64)
+	b.mu.Lock()
+	b.subs[channel] = append(b.subs[channel], ch)
+	b.mu.Unlock()
+	go func() {
+		<-ctx.Done()
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		subs := b.subs[channel]
+		for i, c := range subs {
+			if c == ch {
+				b.subs[channel] = append(subs[:i], subs[i+1:]...)
+				break
+			}
+		}
+		close(ch)
+	}()
+	return ch, nil
+}
+
+// Flush tüm key'leri ve bu backend'i kullanan process içi katmanları siler, testlerde her test öncesi temizlemek için
+func (b *MemoryBackend) Flush() {
+	b.mu.Lock()
+	b.entries = map[string]memoryEntry{}
+	b.mu.Unlock()
+	purgeLocal(b)
+}
+
+type unconfigured struct{}
+
+func (unconfigured) Ping(ctx context.Context) error { return ErrNotConfigured }
+
+func (unconfigured) Get(ctx context.Context, key string) ([]byte, error) {
+	return nil, ErrNotConfigured
+}
+
+func (unconfigured) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return ErrNotConfigured
+}
+
+func (unconfigured) SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	return false, ErrNotConfigured
+}
+
+func (unconfigured) Delete(ctx context.Context, keys ...string) error { return ErrNotConfigured }
+
+func (unconfigured) Exists(ctx context.Context, key string) (bool, error) {
+	return false, ErrNotConfigured
+}
+
+func (unconfigured) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return 0, ErrNotConfigured
+}
+
+func (unconfigured) CompareAndDelete(ctx context.Context, key string, value []byte) (bool, error) {
+	return false, ErrNotConfigured
+}
+
+func (unconfigured) Publish(ctx context.Context, channel, message string) error {
+	return ErrNotConfigured
+}
+
+func (unconfigured) Subscribe(ctx context.Context, channel string) (<-chan string, error) {
+	return nil, ErrNotConfigured
+}
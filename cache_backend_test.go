@@ -0,0 +1,132 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"testing"
+	"time"
+)
+
+// backendContract MemoryBackend ve RedisBackend'in aynı davranması gereken kısımlar
+func backendContract(t *testing.T, b Backend) {
+	ctx := context.Background()
+
+	if _, err := b.Get(ctx, "yok"); !errors.Is(err, ErrNotFound) || !errors.Is(err, redis.Nil) {
+		t.Fatalf("olmayan key: %v", err)
+	}
+	if err := b.Set(ctx, "k", []byte("v"), time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := b.Get(ctx, "k"); err != nil || string(data) != "v" {
+		t.Fatalf("%q %v", data, err)
+	}
+	if ttl, _ := b.TTL(ctx, "k"); ttl <= 0 || ttl > time.Minute {
+		t.Fatalf("ttl %v", ttl)
+	}
+	if ok, _ := b.SetNX(ctx, "k", []byte("x"), time.Minute); ok {
+		t.Fatal("var olan key'e SetNX yazmamalı")
+	}
+	if ok, _ := b.CompareAndDelete(ctx, "k", []byte("baska")); ok {
+		t.Fatal("farklı değerle silinmemeli")
+	}
+	if ok, _ := b.CompareAndDelete(ctx, "k", []byte("v")); !ok {
+		t.Fatal("aynı değerle silinmeli")
+	}
+	if ok, _ := b.Exists(ctx, "k"); ok {
+		t.Fatal("silinen key duruyor")
+	}
+
+	sub, cancel := context.WithCancel(ctx)
+	ch, err := b.Subscribe(sub, "kanal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// redis'te abonelik mesajdan önce hazır olsun
+	time.Sleep(20 * time.Millisecond)
+	b.Publish(ctx, "kanal", "merhaba")
+	select {
+	case msg := <-ch:
+		if msg != "merhaba" {
+			t.Fatal(msg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("mesaj gelmedi")
+	}
+	cancel()
+	select {
+	case _, open := <-ch:
+		if open {
+			t.Fatal("ctx bitince kanal kapanmalı")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("kanal kapanmadı")
+	}
+}
+
+func TestMemoryBackendContract(t *testing.T) {
+	backendContract(t, NewMemoryBackend())
+}
+
+func TestRedisBackendContract(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+	backendContract(t, NewRedisBackend(client))
+}
+
+func TestMemoryBackendExpiry(t *testing.T) {
+	ctx := context.Background()
+	b := NewMemoryBackend()
+	b.Set(ctx, "kisa", []byte("v"), 10*time.Millisecond)
+	b.Set(ctx, "kalici", []byte("v"), 0)
+	time.Sleep(20 * time.Millisecond)
+
+	if _, err := b.Get(ctx, "kisa"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("süresi dolan key dönmemeli: %v", err)
+	}
+	if ok, _ := b.SetNX(ctx, "kisa", []byte("yeni"), time.Minute); !ok {
+		t.Fatal("süresi dolan key'e SetNX yazabilmeli")
+	}
+	if ttl, _ := b.TTL(ctx, "kalici"); ttl != 0 {
+		t.Fatalf("süresiz key'in ttl'i 0 olmalı: %v", ttl)
+	}
+}
+
+func TestMemoryBackendSweepsOnWrites(t *testing.T) {
+	ctx := context.Background()
+	b := NewMemoryBackend()
+	for i := 0; i < 100; i++ {
+		b.Set(ctx, string(rune('a'+i)), []byte("v"), time.Millisecond)
+	}
+	time.Sleep(5 * time.Millisecond)
+	// aynı key'e yazmak map'i büyütmez, temizlik yine de çalışmalı
+	for i := 0; i < 1024; i++ {
+		b.Set(ctx, "sabit", []byte("v"), 0)
+	}
+	b.mu.Lock()
+	n := len(b.entries)
+	b.mu.Unlock()
+	if n != 1 {
+		t.Fatalf("süresi dolan %d key temizlenmedi", n-1)
+	}
+}
+
+func TestSetupFromConfigRejectsUnknownBackend(t *testing.T) {
+	prev := Default()
+	defer Use(prev)
+
+	cfg := &config.Configuration{}
+	cfg.Cache.Backend = "memcached"
+	if err := SetupFromConfig(cfg); err == nil {
+		t.Fatal("bilinmeyen backend hata vermeli")
+	}
+	cfg.Cache.Backend = "memory"
+	if err := SetupFromConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := Default().(*MemoryBackend); !ok {
+		t.Fatalf("%T", Default())
+	}
+}
@@ -0,0 +1,182 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"math"
+	mrand "math/rand"
+	"time"
+)
+
+// GetOrLoad key cache'te varsa döner, yoksa load ile yükleyip ttl ile yazar. ttl 0 ise cache'in TTL'i kullanılır.
+// aynı key için process içinde aynı anda tek load çalışır, replikalar arasında da kısa bir redis kilidi
+// ile tek replika yükler, diğerleri değerin yazılmasını bekler.
+// EarlyRefresh verilmişse süresi dolmak üzere olan key'ler arka planda olasılıksal olarak önceden yenilenir
+//
+//	urunler, err := urunCache.GetOrLoad(ctx, "liste", time.Minute, func(ctx context.Context) ([]model.Urun, error) {
+//		var urunler []model.Urun
+//		return urunler, c.Db.WithContext(ctx).Find(&urunler).Error
+//	})
+func (c *Cache[T]) GetOrLoad(ctx context.Context, key string, ttl time.Duration, load func(ctx context.Context) (T, error)) (T, error) {
+	if ttl <= 0 {
+		ttl = c.ttl
+	}
+	v, remaining, err := c.getWithTTL(ctx, key)
+	if err == nil {
+		if c.shouldRefreshEarly(key, remaining) {
+			go c.refresh(key, ttl, load)
+		}
+		return v, nil
+	}
+
+	// redis'e ulaşılamıyorsa da yükleme tek flight'tan geçer, yoksa kesintide her istek db'ye gider.
+	// yükleme bekleyen herkes için çalıştığından ilk çağıranın iptali diğerlerini düşürmesin
+	ch := c.group.DoChan(key, func() (interface{}, error) {
+		return c.loadLocked(context.WithoutCancel(ctx), key, ttl, load)
+	})
+	var zero T
+	select {
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return zero, res.Err
+		}
+		// T interface ise load nil dönebilir
+		v, _ := res.Val.(T)
+		return v, nil
+	}
+}
+
+// getWithTTL değeri ve kalan süresini tek round-trip'te okur. process içi katman varsa
+// kalan süre bilinmediği için erken yenileme yapılmaz
+func (c *Cache[T]) getWithTTL(ctx context.Context, key string) (T, time.Duration, error) {
+	var v T
+	if c.early <= 0 || c.local != nil {
+		v, err := c.Get(ctx, key)
+		return v, 0, err
+	}
+	data, remaining, err := c.getWithRemaining(ctx, c.prefix+key)
+	if err != nil {
+		return v, 0, err
+	}
+	if err := c.codec.Unmarshal(data, &v); err != nil {
+		return v, 0, err
+	}
+	return v, remaining, nil
+}
+
+// getWithRemaining redis backend'inde tek round-trip, diğerlerinde iki çağrı
+func (c *Cache[T]) getWithRemaining(ctx context.Context, key string) ([]byte, time.Duration, error) {
+	b := c.backend()
+	if rb, ok := b.(*RedisBackend); ok {
+		return rb.GetWithTTL(ctx, key)
+	}
+	data, err := b.Get(ctx, key)
+	if err != nil {
+		return nil, 0, err
+	}
+	remaining, err := b.TTL(ctx, key)
+	return data, remaining, err
+}
+
+// shouldRefreshEarly XFetch: son yüklemenin süresi ne kadar uzunsa o kadar erken yenilenir.
+// süre bu process'te ölçülür, bu key'i hiç yüklememiş replika erken yenilemez
+func (c *Cache[T]) shouldRefreshEarly(key string, remaining time.Duration) bool {
+	if c.deltas == nil || remaining <= 0 {
+		return false
+	}
+	d, ok := c.deltas.Get(key)
+	if !ok {
+		return false
+	}
+	delta := float64(d)
+	return -delta*c.early*math.Log(mrand.Float64()) >= float64(remaining)
+}
+
+func (c *Cache[T]) refresh(key string, ttl time.Duration, load func(ctx context.Context) (T, error)) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.lockTTL)
+	defer cancel()
+	// GetOrLoad'daki yüklemeyle aynı flight'a düşmesin, o değer bekliyor
+	c.refreshGroup.Do(key, func() (interface{}, error) {
+		ok, token := c.lock(ctx, key)
+		if !ok {
+			// başka replika yeniliyor
+			return nil, nil
+		}
+		defer c.unlock(key, token)
+		return c.loadAndSet(ctx, key, ttl, load)
+	})
+}
+
+// loadLocked ctx'in iptali olmamalı. kilidi alan da kilit düşünce kendisi yükleyen de load'ı
+// LockTTL'lik ayrı bir ctx ile çalıştırır, bekleme süresi yüklemenin süresinden yemez
+func (c *Cache[T]) loadLocked(ctx context.Context, key string, ttl time.Duration, load func(ctx context.Context) (T, error)) (T, error) {
+	ok, token := c.lock(ctx, key)
+	if ok {
+		defer c.unlock(key, token)
+		loadCtx, cancel := context.WithTimeout(ctx, c.lockTTL)
+		defer cancel()
+		return c.loadAndSet(loadCtx, key, ttl, load)
+	}
+
+	// kilit başkasında, değerin yazılmasını kilit süresi kadar bekle
+	waitCtx, cancelWait := context.WithTimeout(ctx, c.lockTTL)
+	defer cancelWait()
+	for waitCtx.Err() == nil {
+		timer := time.NewTimer(50 * time.Millisecond)
+		select {
+		case <-waitCtx.Done():
+			timer.Stop()
+		case <-timer.C:
+		}
+		v, err := c.Get(waitCtx, key)
+		if err == nil {
+			return v, nil
+		}
+		if !errors.Is(err, ErrNotFound) {
+			break
+		}
+	}
+	// yükleyen replika düştü veya yetişemedi, kendimiz yüklüyoruz
+	loadCtx, cancel := context.WithTimeout(ctx, c.lockTTL)
+	defer cancel()
+	return c.loadAndSet(loadCtx, key, ttl, load)
+}
+
+func (c *Cache[T]) loadAndSet(ctx context.Context, key string, ttl time.Duration, load func(ctx context.Context) (T, error)) (T, error) {
+	start := time.Now()
+	v, err := load(ctx)
+	if err != nil {
+		return v, err
+	}
+	if c.deltas != nil {
+		c.deltas.Add(key, time.Since(start))
+	}
+	// yazılamazsa yüklenen değer yine döner
+	c.SetTTL(ctx, key, v, ttl)
+	return v, nil
+}
+
+// lock redis hatasında kilitsiz devam edilir
+func (c *Cache[T]) lock(ctx context.Context, key string) (bool, string) {
+	b := make([]byte, 16)
+	rand.Read(b)
+	token := hex.EncodeToString(b)
+	ok, err := c.backend().SetNX(ctx, "lock:"+c.prefix+key, []byte(token), c.lockTTL)
+	if err != nil {
+		return true, ""
+	}
+	return ok, token
+}
+
+func (c *Cache[T]) unlock(key, token string) {
+	if token == "" {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	c.backend().CompareAndDelete(ctx, "lock:"+c.prefix+key, []byte(token))
+}
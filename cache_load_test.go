@@ -0,0 +1,134 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetOrLoadCoalesces(t *testing.T) {
+	ctx := context.Background()
+	c := New[int](Options{Prefix: "yukle:", Backend: NewMemoryBackend()})
+	var loads atomic.Int32
+	load := func(ctx context.Context) (int, error) {
+		loads.Add(1)
+		time.Sleep(30 * time.Millisecond)
+		return 42, nil
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v, err := c.GetOrLoad(ctx, "k", 0, load); err != nil || v != 42 {
+				errs <- errors.Join(err, errors.New("yanlış değer"))
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+	if loads.Load() != 1 {
+		t.Fatalf("load %d kere çalıştı", loads.Load())
+	}
+	// artık cache'ten gelir
+	if v, _ := c.GetOrLoad(ctx, "k", 0, load); v != 42 || loads.Load() != 1 {
+		t.Fatal("cache'teki değer kullanılmadı")
+	}
+}
+
+func TestGetOrLoadFirstCallerCancel(t *testing.T) {
+	c := New[int](Options{Prefix: "iptal:", Backend: NewMemoryBackend()})
+	started := make(chan struct{})
+	load := func(ctx context.Context) (int, error) {
+		close(started)
+		select {
+		case <-time.After(50 * time.Millisecond):
+			return 7, nil
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, err := c.GetOrLoad(ctx, "k", 0, load)
+		first <- err
+	}()
+	<-started
+	second := make(chan int, 1)
+	go func() {
+		v, _ := c.GetOrLoad(context.Background(), "k", 0, load)
+		second <- v
+	}()
+	cancel()
+
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Fatalf("ilk çağıran kendi iptalini almalı: %v", err)
+	}
+	if v := <-second; v != 7 {
+		t.Fatalf("bekleyen çağıran değeri almalı: %d", v)
+	}
+}
+
+func TestGetOrLoadWaitsForOtherReplica(t *testing.T) {
+	ctx := context.Background()
+	mem := NewMemoryBackend()
+	c := New[int](Options{Prefix: "replika:", LockTTL: time.Second, Backend: mem})
+	// başka replika kilidi almış, biraz sonra değeri yazıyor
+	mem.SetNX(ctx, "lock:replika:k", []byte("baska"), time.Second)
+	go func() {
+		time.Sleep(60 * time.Millisecond)
+		c.Set(ctx, "k", 9)
+	}()
+
+	v, err := c.GetOrLoad(ctx, "k", 0, func(ctx context.Context) (int, error) {
+		t.Error("kilit başkasındayken yüklenmemeli")
+		return 0, nil
+	})
+	if err != nil || v != 9 {
+		t.Fatalf("%d %v", v, err)
+	}
+}
+
+func TestGetOrLoadFallbackAfterLockWait(t *testing.T) {
+	ctx := context.Background()
+	mem := NewMemoryBackend()
+	c := New[int](Options{Prefix: "dusen:", LockTTL: 100 * time.Millisecond, Backend: mem})
+	// kilidi alan replika düştü, değer hiç yazılmayacak
+	mem.SetNX(ctx, "lock:dusen:k", []byte("baska"), time.Minute)
+
+	var budget time.Duration
+	v, err := c.GetOrLoad(ctx, "k", 0, func(ctx context.Context) (int, error) {
+		deadline, _ := ctx.Deadline()
+		budget = time.Until(deadline)
+		return 3, ctx.Err()
+	})
+	if err != nil || v != 3 {
+		t.Fatalf("%d %v", v, err)
+	}
+	// bekleme yüklemenin süresinden yememeli
+	if budget < 50*time.Millisecond {
+		t.Fatalf("load'a kalan süre %v", budget)
+	}
+}
+
+func TestGetOrLoadDoesNotCacheErrors(t *testing.T) {
+	ctx := context.Background()
+	c := New[int](Options{Prefix: "hata:", Backend: NewMemoryBackend()})
+	boom := errors.New("db yok")
+	if _, err := c.GetOrLoad(ctx, "k", 0, func(ctx context.Context) (int, error) { return 0, boom }); !errors.Is(err, boom) {
+		t.Fatal(err)
+	}
+	if v, err := c.GetOrLoad(ctx, "k", 0, func(ctx context.Context) (int, error) { return 1, nil }); err != nil || v != 1 {
+		t.Fatalf("%d %v", v, err)
+	}
+}
@@ -0,0 +1,235 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/hashicorp/golang-lru/v2/expirable"
+	"go.uber.org/zap"
+	"strings"
+	"sync"
+	"time"
+)
+
+// invalidationChannel Set ve Delete'te değişen key'ler bu kanala yayınlanır
+const invalidationChannel = "cache:invalidate"
+
+// LocalOptions redis'in önündeki process içi katman
+type LocalOptions struct {
+	// Size en fazla tutulacak key sayısı, dolunca en az kullanılan atılır. default 1000
+	Size int
+	// TTL process içinde tutulma süresi, default cache'in TTL'i. başka replikadaki değişiklik
+	// pub/sub mesajı kaçarsa en geç bu süre sonra görülür
+	TTL time.Duration
+}
+
+type localTier interface {
+	prefix() string
+	remove(key string)
+	purge()
+	// store nil ise katman default backend'i kullanır
+	store() Backend
+}
+
+type local[T any] struct {
+	keyPrefix string
+	backend   Backend
+	lru       *expirable.LRU[string, T]
+	listen    sync.Once
+}
+
+func newLocal[T any](keyPrefix string, backend Backend, opts LocalOptions) *local[T] {
+	l := &local[T]{keyPrefix: keyPrefix, backend: backend, lru: expirable.NewLRU[string, T](opts.Size, nil, opts.TTL)}
+	registerLocal(l)
+	return l
+}
+
+func (l *local[T]) prefix() string { return l.keyPrefix }
+
+func (l *local[T]) remove(key string) { l.lru.Remove(key) }
+
+func (l *local[T]) purge() { l.lru.Purge() }
+
+func (l *local[T]) store() Backend { return l.backend }
+
+// ensureListening katmanın backend'indeki invalidation mesajlarını dinlemeye başlar. Setup'tan
+// önce oluşturulan cache'ler için ilk kullanımda çağrılır, default backend sonradan Use ile
+// değişirse dinleyiciyi Use taşır
+func (l *local[T]) ensureListening() {
+	l.listen.Do(func() {
+		b := l.backend
+		if b == nil {
+			backendMu.RLock()
+			b = backend
+			backendMu.RUnlock()
+		}
+		if b != nil {
+			startListening(b)
+		}
+	})
+}
+
+var (
+	localMu    sync.Mutex
+	localTiers []localTier
+	// broadcastPrefixes Local katmanı olmadığı halde yazınca yayınlanan prefix'ler
+	broadcastPrefixes []string
+	instanceID = newInstanceID()
+	// listeners invalidation kanalını dinlenen backend'ler
+	listeners = map[Backend]context.CancelFunc{}
+)
+
+func newInstanceID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func registerLocal(l localTier) {
+	localMu.Lock()
+	localTiers = append(localTiers, l)
+	localMu.Unlock()
+}
+
+func hasLocalTiers() bool {
+	localMu.Lock()
+	defer localMu.Unlock()
+	return len(localTiers) > 0
+}
+
+// startListening b'nin invalidation kanalını dinlemeye başlar. zaten dinleniyorsa bir şey yapmaz
+func startListening(b Backend) {
+	localMu.Lock()
+	if _, ok := listeners[b]; ok {
+		localMu.Unlock()
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	listeners[b] = cancel
+	localMu.Unlock()
+
+	ch, err := b.Subscribe(ctx, invalidationChannel)
+	if err != nil {
+		localMu.Lock()
+		delete(listeners, b)
+		localMu.Unlock()
+		cancel()
+		return
+	}
+	go func() {
+		for msg := range ch {
+			from, key, found := strings.Cut(msg, "|")
+			if !found || from == instanceID {
+				continue
+			}
+			dropLocal(key)
+		}
+	}()
+}
+
+// stopListening default'tan çıkan backend'in dinleyicisini kapatır. Options.Backend ile
+// açıkça bu backend'i kullanan bir katman varsa dinlemeye devam edilir
+func stopListening(b Backend) {
+	localMu.Lock()
+	defer localMu.Unlock()
+	for _, t := range localTiers {
+		if t.store() == b {
+			return
+		}
+	}
+	if cancel, ok := listeners[b]; ok {
+		cancel()
+		delete(listeners, b)
+	}
+}
+
+// purgeLocal b'yi kullanan process içi katmanları boşaltır
+func purgeLocal(b Backend) {
+	def := Default()
+	localMu.Lock()
+	tiers := append([]localTier(nil), localTiers...)
+	localMu.Unlock()
+	for _, t := range tiers {
+		tb := t.store()
+		if tb == nil {
+			tb = def
+		}
+		if tb == b {
+			t.purge()
+		}
+	}
+}
+
+// Broadcast bu process'te Local katmanı olmayan prefix'lere yazılınca da invalidation yayınlanmasını
+// sağlar. Local katmanı olan prefix'ler zaten yayınlanır, diğer yazmalar redis'e ek mesaj atmaz.
+// Local'i başka bir serviste (worker vs) kullanılan key'lere buradan yazılıyorsa açılışta çağrılmalı
+//
+//	cache.Broadcast("kullanici:", "ayar:")
+func Broadcast(prefixes ...string) {
+	localMu.Lock()
+	broadcastPrefixes = append(broadcastPrefixes, prefixes...)
+	localMu.Unlock()
+}
+
+// shouldPublish key'i Local ile tutan veya Broadcast ile işaretlenen bir prefix'e giriyorsa true
+func shouldPublish(key string) bool {
+	localMu.Lock()
+	defer localMu.Unlock()
+	for _, t := range localTiers {
+		if strings.HasPrefix(key, t.prefix()) {
+			return true
+		}
+	}
+	for _, p := range broadcastPrefixes {
+		if strings.HasPrefix(key, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// invalidateAfterWrite yazma başarılı olduktan sonra çağrılır. sadece Local veya Broadcast
+// prefix'lerinde yayınlanır. yayınlanamazsa yazma geri alınamayacağı için hata dönmek yerine
+// loglanır, diğer replikalar en geç LocalOptions.TTL sonra görür
+func invalidateAfterWrite(ctx context.Context, b Backend, key string) {
+	if !shouldPublish(key) {
+		return
+	}
+	if err := invalidate(ctx, b, key); err != nil {
+		config.Logger("cache").Warn("cache invalidation yayınlanamadı", zap.String("key", key), zap.Error(err))
+	}
+}
+
+func dropLocal(key string) {
+	localMu.Lock()
+	tiers := append([]localTier(nil), localTiers...)
+	localMu.Unlock()
+	for _, t := range tiers {
+		if strings.HasPrefix(key, t.prefix()) {
+			t.remove(strings.TrimPrefix(key, t.prefix()))
+		}
+	}
+}
+
+// Invalidate key'leri bu process'te ve diğer replikalarda process içi katmandan siler.
+// redis'teki değere dokunmaz. Set ve Delete bunu Local ve Broadcast prefix'leri için kendisi yapar.
+// bu process'te Local katman olmasa da yayınlanır, Local kullanan replikalar değişikliği ancak böyle görür.
+// mesaj default backend'e gider, Options.Backend ile kurulan cache'ler kendi backend'ine yayınlar
+func Invalidate(ctx context.Context, keys ...string) error {
+	return invalidate(ctx, Default(), keys...)
+}
+
+func invalidate(ctx context.Context, b Backend, keys ...string) error {
+	for _, key := range keys {
+		dropLocal(key)
+	}
+	if _, ok := b.(unconfigured); ok {
+		return nil
+	}
+	for _, key := range keys {
+		if err := b.Publish(ctx, invalidationChannel, instanceID+"|"+key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
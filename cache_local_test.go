@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLocalTierInvalidatedByOtherReplica(t *testing.T) {
+	ctx := context.Background()
+	mem := NewMemoryBackend()
+	c := New[string](Options{Prefix: "yerel:", TTL: time.Minute, Backend: mem, Local: &LocalOptions{Size: 10}})
+
+	c.Set(ctx, "1", "bir")
+	// redis'teki değer pub/sub olmadan değişirse process içindeki değer döner
+	mem.Set(ctx, "yerel:1", []byte(`"disaridan"`), time.Minute)
+	if v, _ := c.Get(ctx, "1"); v != "bir" {
+		t.Fatalf("process içi katmandan dönmeliydi: %q", v)
+	}
+
+	mem.Publish(ctx, invalidationChannel, "baska-replika|yerel:1")
+	deadline := time.Now().Add(time.Second)
+	for {
+		if v, _ := c.Get(ctx, "1"); v == "disaridan" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("invalidation mesajı katmanı düşürmedi")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestLocalTierSize(t *testing.T) {
+	ctx := context.Background()
+	c := New[int](Options{Prefix: "boyut:", Backend: NewMemoryBackend(), Local: &LocalOptions{Size: 2}})
+	for i, k := range []string{"a", "b", "c"} {
+		c.Set(ctx, k, i)
+	}
+	if n := c.local.lru.Len(); n != 2 {
+		t.Fatalf("katmanda %d key var", n)
+	}
+}
+
+func TestWritesPublishOnlyForLocalOrBroadcastPrefixes(t *testing.T) {
+	ctx := context.Background()
+	mem := NewMemoryBackend()
+	sub, cancel := context.WithCancel(ctx)
+	defer cancel()
+	msgs, _ := mem.Subscribe(sub, invalidationChannel)
+
+	sessiz := New[int](Options{Prefix: "sessiz:", Backend: mem})
+	yayin := New[int](Options{Prefix: "yayin:", Backend: mem, Broadcast: true})
+	sessiz.Set(ctx, "a", 1)
+	yayin.Set(ctx, "a", 1)
+
+	select {
+	case msg := <-msgs:
+		if msg != instanceID+"|yayin:a" {
+			t.Fatalf("beklenmeyen mesaj %q", msg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Broadcast prefix'i yayınlanmadı")
+	}
+	select {
+	case msg := <-msgs:
+		t.Fatalf("fazladan mesaj %q", msg)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	// Invalidate açıkça çağrılınca her zaman yayınlanır
+	if err := invalidate(ctx, mem, "sessiz:a"); err != nil {
+		t.Fatal(err)
+	}
+	if msg := <-msgs; msg != instanceID+"|sessiz:a" {
+		t.Fatal(msg)
+	}
+}
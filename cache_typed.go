@@ -0,0 +1,252 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	lru "github.com/hashicorp/golang-lru/v2"
+	"github.com/vmihailenco/msgpack/v5"
+	"golang.org/x/sync/singleflight"
+	"google.golang.org/protobuf/proto"
+	"reflect"
+	"time"
+)
+
+// ErrNotFound key cache'te yoksa. errors.Is(err, cache.ErrNotFound) ile kontrol edilir
+var ErrNotFound = errors.New("cache: kayıt bulunamadı")
+
+// NotFoundError hangi key'in bulunamadığı. eski kodlar kırılmasın diye redis.Nil ile de eşleşir
+type NotFoundError struct {
+	Key string
+}
+
+func (e *NotFoundError) Error() string {
+	return "cache: kayıt bulunamadı: " + e.Key
+}
+
+func (e *NotFoundError) Is(target error) bool {
+	return target == ErrNotFound || target == redis.Nil
+}
+
+// Codec değerlerin redis'e hangi formatta yazılacağı
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+var (
+	JSON     Codec = jsonCodec{}
+	Msgpack  Codec = msgpackCodec{}
+	Gob      Codec = gobCodec{}
+	Protobuf Codec = protobufCodec{}
+)
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) { return json.Marshal(v) }
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+
+type msgpackCodec struct{}
+
+func (msgpackCodec) Marshal(v interface{}) ([]byte, error) { return msgpack.Marshal(v) }
+
+func (msgpackCodec) Unmarshal(data []byte, v interface{}) error { return msgpack.Unmarshal(data, v) }
+
+type gobCodec struct{}
+
+func (gobCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gobCodec) Unmarshal(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// protobufCodec Cache[*pb.Mesaj] şeklinde kullanılır, mesaj okurken oluşturulur
+type protobufCodec struct{}
+
+func (protobufCodec) Marshal(v interface{}) ([]byte, error) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("cache: %T proto.Message değil", v)
+	}
+	return proto.Marshal(m)
+}
+
+func (protobufCodec) Unmarshal(data []byte, v interface{}) error {
+	if m, ok := v.(proto.Message); ok {
+		return proto.Unmarshal(data, m)
+	}
+	// v **Mesaj ise mesaj oluşturulup içine yazılır
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr && rv.Elem().Kind() == reflect.Ptr {
+		msg := reflect.New(rv.Elem().Type().Elem())
+		if m, ok := msg.Interface().(proto.Message); ok {
+			if err := proto.Unmarshal(data, m); err != nil {
+				return err
+			}
+			rv.Elem().Set(msg)
+			return nil
+		}
+	}
+	return fmt.Errorf("cache: %T'ye protobuf decode edilemez", v)
+}
+
+// DefaultTTL süre verilmeden yapılan yazmalarda kullanılır. Options.TTL'i olmayan cache'ler ve
+// AppCtx.SetToCache bunu kullanır, uygulama açılırken değiştirilebilir
+var DefaultTTL = 10 * time.Second
+
+// Options zero değerler default'a döner
+type Options struct {
+	// Prefix tüm key'lerin önüne eklenir, örn. "kullanici:"
+	Prefix string
+	// TTL Set'te kullanılan süre, default DefaultTTL
+	TTL   time.Duration
+	Codec Codec
+	// LockTTL GetOrLoad'da replikalar arası yükleme kilidinin süresi, default 5sn.
+	// load'a verilen ctx de bu süreyle sınırlıdır, çağıranın ctx'i iptal olsa da yükleme sürer
+	LockTTL time.Duration
+	// EarlyRefresh GetOrLoad'da olasılıksal erken yenileme katsayısı, 0 ise kapalı. 1 çoğu durumda yeterli
+	EarlyRefresh float64
+	// Local verilirse değerler redis'ten önce process içinde de tutulur. bir replikada Set veya
+	// Delete yapılınca diğerleri cache'in backend'i üzerinden pub/sub ile key'i düşer. dönen
+	// değerler paylaşıldığı için pointer, slice ve map tiplerinde değiştirilmemeli
+	Local *LocalOptions
+	// Broadcast Local kullanmayan ama başka process'lerde Local ile okunan prefix'e yazan cache'ler
+	// için. verilirse Set ve Delete'te invalidation yayınlanır, bkz. cache.Broadcast
+	Broadcast bool
+	// Backend nil ise default backend kullanılır, bkz. Use
+	Backend Backend
+}
+
+// Cache T tipindeki değerler için tipli cache
+//
+//	var kullanicilar = cache.New[model.Kullanici](cache.Options{Prefix: "kullanici:", TTL: time.Minute})
+//
+//	k, err := kullanicilar.Get(ctx, strconv.FormatInt(id, 10))
+//	if errors.Is(err, cache.ErrNotFound) {
+//		...
+//	}
+type Cache[T any] struct {
+	prefix  string
+	ttl     time.Duration
+	codec   Codec
+	lockTTL time.Duration
+	early   float64
+	local   *local[T]
+	store   Backend
+
+	group singleflight.Group
+	// refreshGroup erken yenilemeler için, GetOrLoad'ın flight'larıyla karışmasın
+	refreshGroup singleflight.Group
+	// deltas EarlyRefresh için key başına son yükleme süresi. sınırsız büyümesin diye LRU
+	deltas *lru.Cache[string, time.Duration]
+}
+
+// maxDeltas EarlyRefresh'in süresini hatırladığı en fazla key. atılan key'ler tekrar yüklenene
+// kadar erken yenilenmez
+const maxDeltas = 10000
+
+func New[T any](opts Options) *Cache[T] {
+	if opts.TTL <= 0 {
+		opts.TTL = DefaultTTL
+	}
+	if opts.Codec == nil {
+		opts.Codec = JSON
+	}
+	if opts.LockTTL <= 0 {
+		opts.LockTTL = 5 * time.Second
+	}
+	c := &Cache[T]{
+		prefix:  opts.Prefix,
+		ttl:     opts.TTL,
+		codec:   opts.Codec,
+		lockTTL: opts.LockTTL,
+		early:   opts.EarlyRefresh,
+		store:   opts.Backend,
+	}
+	if opts.Local != nil {
+		l := *opts.Local
+		if l.Size <= 0 {
+			l.Size = 1000
+		}
+		if l.TTL <= 0 {
+			l.TTL = opts.TTL
+		}
+		c.local = newLocal[T](opts.Prefix, opts.Backend, l)
+	}
+	if opts.Broadcast {
+		Broadcast(opts.Prefix)
+	}
+	if opts.EarlyRefresh > 0 {
+		c.deltas, _ = lru.New[string, time.Duration](maxDeltas)
+	}
+	return c
+}
+
+func (c *Cache[T]) backend() Backend {
+	if c.store != nil {
+		return c.store
+	}
+	return Default()
+}
+
+func (c *Cache[T]) Get(ctx context.Context, key string) (T, error) {
+	if c.local != nil {
+		c.local.ensureListening()
+		if v, ok := c.local.lru.Get(key); ok {
+			return v, nil
+		}
+	}
+	var v T
+	data, err := c.backend().Get(ctx, c.prefix+key)
+	if err != nil {
+		return v, err
+	}
+	if err := c.codec.Unmarshal(data, &v); err != nil {
+		return v, fmt.Errorf("cache: %s decode edilemedi: %w", c.prefix+key, err)
+	}
+	if c.local != nil {
+		c.local.lru.Add(key, v)
+	}
+	return v, nil
+}
+
+// Set değeri cache'in default TTL'i ile yazar
+func (c *Cache[T]) Set(ctx context.Context, key string, v T) error {
+	return c.SetTTL(ctx, key, v, c.ttl)
+}
+
+func (c *Cache[T]) SetTTL(ctx context.Context, key string, v T, ttl time.Duration) error {
+	data, err := c.codec.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("cache: %s encode edilemedi: %w", c.prefix+key, err)
+	}
+	if err := c.backend().Set(ctx, c.prefix+key, data, ttl); err != nil {
+		return err
+	}
+	// Local'i olmayan cache de yayınlar, aynı prefix'i Local ile kullanan replikalar olabilir
+	invalidateAfterWrite(ctx, c.backend(), c.prefix+key)
+	if c.local != nil {
+		c.local.ensureListening()
+		c.local.lru.Add(key, v)
+	}
+	return nil
+}
+
+func (c *Cache[T]) Delete(ctx context.Context, key string) error {
+	if err := c.backend().Delete(ctx, c.prefix+key); err != nil {
+		return err
+	}
+	invalidateAfterWrite(ctx, c.backend(), c.prefix+key)
+	return nil
+}
@@ -0,0 +1,89 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	"testing"
+	"time"
+)
+
+type codecKayit struct {
+	ID     int64
+	Ad     string
+	Etiket []string
+}
+
+func TestCodecRoundTrip(t *testing.T) {
+	in := codecKayit{ID: 5, Ad: "ayşe", Etiket: []string{"a", "b"}}
+	for name, codec := range map[string]Codec{"json": JSON, "msgpack": Msgpack, "gob": Gob} {
+		data, err := codec.Marshal(in)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		var out codecKayit
+		if err := codec.Unmarshal(data, &out); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if out.ID != in.ID || out.Ad != in.Ad || len(out.Etiket) != 2 || out.Etiket[1] != "b" {
+			t.Fatalf("%s: %+v", name, out)
+		}
+	}
+
+	data, err := Protobuf.Marshal(wrapperspb.String("x"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := new(wrapperspb.StringValue)
+	if err := Protobuf.Unmarshal(data, &out); err != nil || out.GetValue() != "x" {
+		t.Fatalf("protobuf: %v %v", out, err)
+	}
+	if _, err := Protobuf.Marshal(in); err == nil {
+		t.Fatal("proto.Message olmayan tip hata vermeli")
+	}
+}
+
+func TestTypedCacheGetSetDelete(t *testing.T) {
+	ctx := context.Background()
+	mem := NewMemoryBackend()
+	c := New[codecKayit](Options{Prefix: "kayit:", Codec: Msgpack, Backend: mem})
+
+	if _, err := c.Get(ctx, "1"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("olmayan key: %v", err)
+	}
+	if err := c.Set(ctx, "1", codecKayit{ID: 1, Ad: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := c.Get(ctx, "1"); err != nil || v.Ad != "a" {
+		t.Fatalf("%+v %v", v, err)
+	}
+	// prefix ile yazılır, TTL verilmediği için DefaultTTL kullanılır
+	if ttl, _ := mem.TTL(ctx, "kayit:1"); ttl <= 0 || ttl > DefaultTTL {
+		t.Fatalf("ttl %v", ttl)
+	}
+	if err := c.SetTTL(ctx, "2", codecKayit{ID: 2}, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if ttl, _ := mem.TTL(ctx, "kayit:2"); ttl <= DefaultTTL {
+		t.Fatalf("ttl %v", ttl)
+	}
+	if err := c.Delete(ctx, "1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Get(ctx, "1"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("silinen key: %v", err)
+	}
+}
+
+func TestTypedCacheUsesDefaultBackend(t *testing.T) {
+	prev := Default()
+	defer Use(prev)
+	mem := NewMemoryBackend()
+	Use(mem)
+
+	c := New[int](Options{Prefix: "sayi:"})
+	c.Set(context.Background(), "a", 3)
+	if ok, _ := mem.Exists(context.Background(), "sayi:a"); !ok {
+		t.Fatal("Backend verilmeyen cache default backend'e yazmalı")
+	}
+}
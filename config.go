@@ -22,6 +22,9 @@ type Configuration struct {
 	Server       ServerConfig
 	Database     DbConfig
 	Redis        RedisConfig
+	Cache        CacheConfig
+	HttpClients  map[string]HttpClientConfig
+	Tracing      TracingConfig
 }
 
 type ServerConfig struct {
@@ -55,6 +58,57 @@ type RedisConfig struct {
 	WriteTimeout time.Duration
 }
 
+type CacheConfig struct {
+	Backend string `default:"redis"` // redis,memory. memory tek instance ve geliştirme için
+}
+
+type TracingConfig struct {
+	Enabled     bool    `default:"false"`
+	Exporter    string  `default:"stdout"`         // stdout,otlp
+	Endpoint    string  `default:"localhost:4318"` // otlp http collector adresi
+	Insecure    bool    `default:"true"`
+	ServiceName string  `default:"web"`
+	SampleRatio float64 `default:"1"`
+}
+
+// HttpClientConfig dışarıya istek atan isimli client'lar için. süreler saniye cinsinden,
+// boş bırakılan alanlar utils'teki default değerleri alır (bkz. utils.withDefaults)
+//
+//	httpClients:
+//	  partner:
+//	    baseURL: https://api.partner.com/v1
+//	    timeout: 10
+//	    caFile: /certs/partner-ca.pem
+//	    headers:
+//	      X-Api-Key: xxx
+type HttpClientConfig struct {
+	BaseURL             string
+	Timeout             int // 30, negatifse timeout yok, süre ctx ile belirlenir
+	DialTimeout         int
+	TLSHandshakeTimeout int
+	IdleConnTimeout     int
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+	MaxConnsPerHost     int
+	Proxy               string
+	CAFile              string
+	CertFile            string
+	KeyFile             string
+	InsecureSkipVerify  bool
+	Headers             map[string]string
+	RateLimit           float64 // saniyede en fazla istek, 0 ise sınır yok
+	RateBurst           int
+	SharedRateLimit     bool   // true ise sınır redis üzerinden tüm replikalarda ortak
+	MaxResponseSize     int64  // byte, 10MB, açılmış body için de geçerli. negatifse sınır yok
+	SigningSecret       string // verilirse istekler HMAC ile imzalanır
+	SignatureHeader     string // X-Signature
+	TimestampHeader     string // X-Signature-Timestamp
+	TokenURL            string // verilirse OAuth2 client credentials ile token alınır
+	ClientID            string
+	ClientSecret        string
+	Scopes              []string
+}
+
 func setDefaults() {
 	viper.SetDefault("isProduction", os.Getenv("APP_ENV") == "PRODUCTION")
 	viper.SetDefault("server.domain", "http://localhost")
@@ -81,6 +135,15 @@ func setDefaults() {
 	viper.SetDefault("redis.password", "asdf")
 	viper.SetDefault("redis.db", 9)
 	viper.SetDefault("redis.writeTimeout", time.Second*5)
+
+	viper.SetDefault("cache.backend", "redis")
+
+	viper.SetDefault("tracing.enabled", false)
+	viper.SetDefault("tracing.exporter", "stdout")
+	viper.SetDefault("tracing.endpoint", "localhost:4318")
+	viper.SetDefault("tracing.insecure", true)
+	viper.SetDefault("tracing.serviceName", appName)
+	viper.SetDefault("tracing.sampleRatio", 1)
 }
 
 // os environment'ından okumak için. şimdilik çokda lazım değil..
@@ -156,3 +219,8 @@ func Setup() (*Configuration, error) {
 func Get() *Configuration {
 	return configuration
 }
+
+// Set dosyadan okumadan konfigürasyon vermek için. testlerde kullanılıyor
+func Set(cfg *Configuration) {
+	configuration = cfg
+}
@@ -8,7 +8,6 @@ import (
 	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
 	turkish "github.com/go-playground/validator/v10/translations/tr"
-	"github.com/go-redis/redis/v8"
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
@@ -46,6 +45,14 @@ type AppCtx struct {
 	*fiber.Ctx
 	Db         *gorm.DB
 	AuditModel *model.Audit
+	// Cache nil ise cache.Default() kullanılır
+	Cache cache.Backend
+}
+
+// NewAppCtx istek için AppCtx oluşturur. db istek context'i ile verilir,
+// sorgular istek iptal olunca durur ve trace'e eklenir. store nil ise cache.Default() kullanılır
+func NewAppCtx(c *fiber.Ctx, db *gorm.DB, store cache.Backend) *AppCtx {
+	return &AppCtx{Ctx: c, Db: db.WithContext(c.UserContext()), Cache: store}
 }
 
 func (c *AppCtx) SuccessResponse(data interface{}) error {
@@ -139,12 +146,22 @@ func (c *AppCtx) GetLang() string {
 	return l
 }
 
+func (c *AppCtx) cache() cache.Backend {
+	if c.Cache != nil {
+		return c.Cache
+	}
+	return cache.Default()
+}
+
+// GetFromCache key yoksa *cache.NotFoundError döner. tipli kullanım için bkz. cache.New
 func (c *AppCtx) GetFromCache(key string, model interface{}) error {
-	result, err := cache.Get(c.Context(), key)
+	result, err := c.cache().Get(c.UserContext(), key)
 	if err != nil {
-		if errors.Is(err, redis.Nil) {
-			return err
+		if errors.Is(err, cache.ErrNotFound) {
+			metrics.CacheMiss()
+			return &cache.NotFoundError{Key: key}
 		}
+		metrics.CacheError()
 		_, file, no, ok := runtime.Caller(1)
 		var filename string
 		if ok {
@@ -154,7 +171,8 @@ func (c *AppCtx) GetFromCache(key string, model interface{}) error {
 		return err
 	}
 
-	err = json.Unmarshal([]byte(result), model)
+	metrics.CacheHit()
+	err = json.Unmarshal(result, model)
 	if err != nil {
 		_, file, no, ok := runtime.Caller(1)
 		var filename string
@@ -167,6 +185,7 @@ func (c *AppCtx) GetFromCache(key string, model interface{}) error {
 	return nil
 }
 
+// SetToCache cache.DefaultTTL süresiyle yazar
 func (c *AppCtx) SetToCache(key string, model interface{}) {
 	data, err := json.Marshal(model)
 	if err != nil {
@@ -178,7 +197,7 @@ func (c *AppCtx) SetToCache(key string, model interface{}) {
 		c.Log().Error("setcache from: "+filename, zap.Error(err))
 	}
 
-	err = cache.Set(c.Context(), key, data, 10*time.Second)
+	err = cache.SetWith(c.UserContext(), c.cache(), key, data, cache.DefaultTTL)
 	if err != nil {
 		_, file, no, ok := runtime.Caller(1)
 		var filename string
@@ -200,7 +219,7 @@ func (c *AppCtx) SetToCacheTTL(key string, model interface{}, duration time.Dura
 		c.Log().Error("setcache from: "+filename, zap.Error(err))
 	}
 
-	err = cache.Set(c.Context(), key, data, duration)
+	err = cache.SetWith(c.UserContext(), c.cache(), key, data, duration)
 	if err != nil {
 		_, file, no, ok := runtime.Caller(1)
 		var filename string
@@ -17,6 +17,20 @@ const (
 	ErrorTypeNotLogged
 )
 
+func (t ErrorType) String() string {
+	switch t {
+	case ErrorTypeBadRequest:
+		return "bad_request"
+	case ErrorTypeNotFound:
+		return "not_found"
+	case ErrorTypeInternal:
+		return "internal"
+	case ErrorTypeNotLogged:
+		return "not_logged"
+	}
+	return "no_type"
+}
+
 type myError struct {
 	errorType     ErrorType
 	originalError error
@@ -67,8 +81,13 @@ func GetType(err error) ErrorType {
 	return ErrorTypeNoType
 }
 
+// handledErrorLocal ErrorHandler'ın işlediği hata. metrics middleware'i gibi hatayı kendisi
+// işleyip nil dönen middleware'lerin dışındakiler hatayı buradan görür
+const handledErrorLocal = "handledError"
+
 // ErrorHandler fiber için
 func ErrorHandler(c *fiber.Ctx, err error) error {
+	c.Locals(handledErrorLocal, err)
 	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSONCharsetUTF8)
 	respModel := &context.ResponseModel{
 		HataVarMi: true,
@@ -77,6 +96,7 @@ func ErrorHandler(c *fiber.Ctx, err error) error {
 
 	ctxRqId := c.Get("requestid", "")
 	logger := config.Logger(ctxRqId)
+	metrics.Errors.WithLabelValues(GetType(err).String()).Inc()
 
 	if GetType(err) == ErrorTypeNoType {
 		if err != nil {
@@ -0,0 +1,204 @@
+package utils
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/andybalholm/brotli"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ErrResponseTooLarge response body'si izin verilen boyutu aştığında. errors.Is ile kontrol edilir
+var ErrResponseTooLarge = errors.New("response çok büyük")
+
+// ResponseTooLargeError hangi istekte hangi sınırın aşıldığı.
+// Decompressed true ise sınır açılmış body'de aşılmıştır, büyük ihtimalle sıkıştırma bombası
+type ResponseTooLargeError struct {
+	URL          string
+	Limit        int64
+	Decompressed bool
+}
+
+func (e *ResponseTooLargeError) Error() string {
+	if e.Decompressed {
+		return fmt.Sprintf("response açıldıktan sonra %d byte sınırını aştı: %s", e.Limit, e.URL)
+	}
+	return fmt.Sprintf("response %d byte sınırını aştı: %s", e.Limit, e.URL)
+}
+
+func (e *ResponseTooLargeError) Is(target error) bool {
+	return target == ErrResponseTooLarge
+}
+
+type maxResponseSizeKey struct{}
+
+// WithMaxResponseSize bu context ile atılan istekler için client'ın sınırını ezer. negatifse sınır yok
+//
+//	ctx = utils.WithMaxResponseSize(ctx, 500<<20)
+//	_, err := partner.NewRequest(http.MethodGet, "/arsiv.zip").DownloadFile(ctx, path, utils.DownloadOptions{})
+func WithMaxResponseSize(ctx context.Context, n int64) context.Context {
+	return context.WithValue(ctx, maxResponseSizeKey{}, n)
+}
+
+func maxResponseSizeFrom(ctx context.Context, def int64) int64 {
+	if n, ok := ctx.Value(maxResponseSizeKey{}).(int64); ok {
+		return n
+	}
+	return def
+}
+
+// MaxResponseSize sadece bu istek için response boyut sınırı, bkz. WithMaxResponseSize
+func (r *Request) MaxResponseSize(n int64) *Request {
+	r.maxResponseSize = n
+	return r
+}
+
+// LimitResponse response body'sini max byte ile sınırlar ve gzip, deflate, br cevapları açar.
+// sınır hem sıkıştırılmış hem açılmış body'e uygulanır, aşılırsa body okunurken
+// *ResponseTooLargeError döner. istekte Accept-Encoding verilmişse body açılmadan bırakılır
+func LimitResponse(max int64) Interceptor {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			limit := maxResponseSizeFrom(req.Context(), max)
+
+			decompress := req.Header.Get("Accept-Encoding") == "" && req.Method != http.MethodHead
+			if decompress {
+				req = req.Clone(req.Context())
+				req.Header.Set("Accept-Encoding", "gzip, deflate, br")
+			}
+			res, err := next.RoundTrip(req)
+			if err != nil || res.Body == nil || res.Body == http.NoBody {
+				return res, err
+			}
+
+			target := req.URL.Redacted()
+			body := io.ReadCloser(res.Body)
+			if limit > 0 {
+				if res.ContentLength > limit {
+					res.Body.Close()
+					body = errBody{err: &ResponseTooLargeError{URL: target, Limit: limit}}
+				} else {
+					body = newLimitedBody(body, limit, &ResponseTooLargeError{URL: target, Limit: limit})
+				}
+			}
+
+			encoding := strings.ToLower(strings.TrimSpace(res.Header.Get("Content-Encoding")))
+			if decompress && isSupportedEncoding(encoding) {
+				body = &decompressBody{raw: body, encoding: encoding}
+				if limit > 0 {
+					body = newLimitedBody(body, limit, &ResponseTooLargeError{URL: target, Limit: limit, Decompressed: true})
+				}
+				res.Header.Del("Content-Encoding")
+				res.Header.Del("Content-Length")
+				res.ContentLength = -1
+				res.Uncompressed = true
+			}
+			res.Body = body
+			return res, nil
+		})
+	}
+}
+
+func isSupportedEncoding(encoding string) bool {
+	switch encoding {
+	case "gzip", "x-gzip", "deflate", "br":
+		return true
+	}
+	return false
+}
+
+// limitedBody limit'ten fazla byte okunursa err döner
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+	err       error
+}
+
+func newLimitedBody(rc io.ReadCloser, limit int64, err error) *limitedBody {
+	return &limitedBody{ReadCloser: rc, remaining: limit, err: err}
+}
+
+func (l *limitedBody) Read(p []byte) (int, error) {
+	if l.remaining < 0 {
+		return 0, l.err
+	}
+	// sınırın aşıldığını anlamak için bir byte fazla okunur
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+	n, err := l.ReadCloser.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return n + int(l.remaining), l.err
+	}
+	return n, err
+}
+
+type errBody struct {
+	err error
+}
+
+func (b errBody) Read(p []byte) (int, error) { return 0, b.err }
+
+func (b errBody) Close() error { return nil }
+
+// decompressBody decoder'ı ilk okumada oluşturur, böylece bozuk header RoundTrip'te değil okurken hata verir
+type decompressBody struct {
+	raw      io.ReadCloser
+	encoding string
+	r        io.Reader
+	closer   io.Closer
+}
+
+func (d *decompressBody) Read(p []byte) (int, error) {
+	if d.r == nil {
+		if err := d.init(); err != nil {
+			return 0, err
+		}
+	}
+	return d.r.Read(p)
+}
+
+func (d *decompressBody) init() error {
+	switch d.encoding {
+	case "gzip", "x-gzip":
+		zr, err := gzip.NewReader(d.raw)
+		if err != nil {
+			return err
+		}
+		d.r, d.closer = zr, zr
+	case "deflate":
+		// deflate çoğu sunucuda zlib, bazılarında ham flate olarak gelir
+		br := bufio.NewReader(d.raw)
+		if head, err := br.Peek(2); err == nil && isZlibHeader(head) {
+			zr, err := zlib.NewReader(br)
+			if err != nil {
+				return err
+			}
+			d.r, d.closer = zr, zr
+		} else {
+			fr := flate.NewReader(br)
+			d.r, d.closer = fr, fr
+		}
+	case "br":
+		d.r = brotli.NewReader(d.raw)
+	}
+	return nil
+}
+
+func isZlibHeader(b []byte) bool {
+	return b[0]&0x0f == 8 && (uint16(b[0])<<8|uint16(b[1]))%31 == 0
+}
+
+func (d *decompressBody) Close() error {
+	if d.closer != nil {
+		d.closer.Close()
+	}
+	return d.raw.Close()
+}
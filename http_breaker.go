@@ -0,0 +1,236 @@
+package utils
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// BreakerState bir upstream host için circuit breaker durumu
+type BreakerState int
+
+const (
+	// BreakerClosed istekler normal akar
+	BreakerClosed BreakerState = iota
+	// BreakerOpen istekler upstream'e gitmeden ErrCircuitOpen ile döner
+	BreakerOpen
+	// BreakerHalfOpen OpenTimeout dolduktan sonra sınırlı sayıda deneme isteğine izin verilir
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// BreakerSettings circuit breaker eşikleri
+type BreakerSettings struct {
+	// FailureThreshold art arda bu kadar hata olursa breaker açılır
+	FailureThreshold int
+	// OpenTimeout breaker bu süre açık kalır, sonra half-open'a geçer
+	OpenTimeout time.Duration
+	// HalfOpenMaxRequests half-open'da izin verilen deneme isteği sayısı.
+	// hepsi başarılı olursa breaker kapanır, biri bile hata alırsa tekrar açılır
+	HalfOpenMaxRequests int
+	// OnStateChange durum değiştiğinde çağrılır. log, metrik vs için
+	OnStateChange func(host string, from, to BreakerState)
+}
+
+// DefaultBreakerSettings paylaşılan http client'ın kullandığı ayarlar
+var DefaultBreakerSettings = BreakerSettings{
+	FailureThreshold:    5,
+	OpenTimeout:         30 * time.Second,
+	HalfOpenMaxRequests: 1,
+}
+
+// ErrCircuitOpen breaker açıkken dönen hata. errors.Is ile kontrol edilebilir
+var ErrCircuitOpen = errors.New("circuit breaker açık")
+
+// CircuitOpenError hangi host için ve ne zamana kadar breaker'ın açık olduğunu taşır
+type CircuitOpenError struct {
+	Host    string
+	RetryAt time.Time
+}
+
+func (e *CircuitOpenError) Error() string {
+	return "circuit breaker açık: " + e.Host + ", tekrar deneme: " + e.RetryAt.Format(time.RFC3339)
+}
+
+func (e *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+// BreakerStatus health check ve metrikler için breaker'ın anlık durumu
+type BreakerStatus struct {
+	Client   string       `json:"client"`
+	Host     string       `json:"host"`
+	State    BreakerState `json:"-"`
+	StateStr string       `json:"state"`
+	Failures int          `json:"failures"`
+	OpenedAt *time.Time   `json:"openedAt,omitempty"` // breaker kapalıyken nil
+}
+
+type circuitBreaker struct {
+	mu       sync.Mutex
+	host     string
+	settings BreakerSettings
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	inFlight int
+	success  int
+}
+
+func (b *circuitBreaker) setState(to BreakerState) {
+	from := b.state
+	b.state = to
+	b.failures = 0
+	b.inFlight = 0
+	b.success = 0
+	if to == BreakerOpen {
+		b.openedAt = time.Now()
+	}
+	if from != to && b.settings.OnStateChange != nil {
+		go b.settings.OnStateChange(b.host, from, to)
+	}
+}
+
+func (b *circuitBreaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerOpen {
+		retryAt := b.openedAt.Add(b.settings.OpenTimeout)
+		if time.Now().Before(retryAt) {
+			return &CircuitOpenError{Host: b.host, RetryAt: retryAt}
+		}
+		b.setState(BreakerHalfOpen)
+	}
+	if b.state == BreakerHalfOpen {
+		if b.inFlight >= b.settings.HalfOpenMaxRequests {
+			return &CircuitOpenError{Host: b.host, RetryAt: time.Now().Add(time.Second)}
+		}
+		b.inFlight++
+	}
+	return nil
+}
+
+func (b *circuitBreaker) record(ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerClosed:
+		if ok {
+			b.failures = 0
+			return
+		}
+		b.failures++
+		if b.failures >= b.settings.FailureThreshold {
+			b.setState(BreakerOpen)
+		}
+	case BreakerHalfOpen:
+		if !ok {
+			b.setState(BreakerOpen)
+			return
+		}
+		b.success++
+		if b.success >= b.settings.HalfOpenMaxRequests {
+			b.setState(BreakerClosed)
+		}
+	}
+}
+
+// release sonucu sayılmayacak bir isteğin half-open slotunu geri verir
+func (b *circuitBreaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == BreakerHalfOpen && b.inFlight > 0 {
+		b.inFlight--
+	}
+}
+
+func (b *circuitBreaker) status() BreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	st := BreakerStatus{Host: b.host, State: b.state, StateStr: b.state.String(), Failures: b.failures}
+	if b.state != BreakerClosed {
+		openedAt := b.openedAt
+		st.OpenedAt = &openedAt
+	}
+	return st
+}
+
+type breakerTransport struct {
+	next     http.RoundTripper
+	settings BreakerSettings
+
+	mu       sync.Mutex
+	breakers map[string]*circuitBreaker
+}
+
+func newBreakerTransport(next http.RoundTripper, settings BreakerSettings) *breakerTransport {
+	if settings.FailureThreshold <= 0 {
+		settings.FailureThreshold = DefaultBreakerSettings.FailureThreshold
+	}
+	if settings.OpenTimeout <= 0 {
+		settings.OpenTimeout = DefaultBreakerSettings.OpenTimeout
+	}
+	if settings.HalfOpenMaxRequests <= 0 {
+		settings.HalfOpenMaxRequests = 1
+	}
+	return &breakerTransport{next: next, settings: settings, breakers: map[string]*circuitBreaker{}}
+}
+
+func (t *breakerTransport) breaker(host string) *circuitBreaker {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	b, ok := t.breakers[host]
+	if !ok {
+		b = &circuitBreaker{host: host, settings: t.settings}
+		t.breakers[host] = b
+	}
+	return b
+}
+
+func (t *breakerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	b := t.breaker(req.URL.Host)
+	if err := b.allow(); err != nil {
+		closeRequestBody(req)
+		return nil, err
+	}
+
+	res, err := t.next.RoundTrip(req)
+	// caller'ın iptal ettiği istekler upstream'in suçu değil
+	if err != nil && req.Context().Err() != nil {
+		b.release()
+		return res, err
+	}
+	b.record(err == nil && res.StatusCode < 500)
+	return res, err
+}
+
+func (t *breakerTransport) statuses() []BreakerStatus {
+	t.mu.Lock()
+	list := make([]*circuitBreaker, 0, len(t.breakers))
+	for _, b := range t.breakers {
+		list = append(list, b)
+	}
+	t.mu.Unlock()
+
+	result := make([]BreakerStatus, 0, len(list))
+	for _, b := range list {
+		result = append(result, b.status())
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Host < result[j].Host })
+	return result
+}
@@ -0,0 +1,106 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBreakerOpensAndRecovers(t *testing.T) {
+	status := 500
+	calls := 0
+	next := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		return &http.Response{StatusCode: status, Body: http.NoBody}, nil
+	})
+	tr := newBreakerTransport(next, BreakerSettings{FailureThreshold: 2, OpenTimeout: 30 * time.Millisecond})
+	get := func() error {
+		req, _ := http.NewRequest(http.MethodGet, "http://api.test/x", nil)
+		_, err := tr.RoundTrip(req)
+		return err
+	}
+
+	get()
+	get()
+	if err := get(); !errors.Is(err, ErrCircuitOpen) || calls != 2 {
+		t.Fatalf("breaker açılmadı: err=%v calls=%d", err, calls)
+	}
+	st := tr.statuses()[0]
+	if st.State != BreakerOpen || st.OpenedAt == nil {
+		t.Fatalf("status %+v", st)
+	}
+
+	time.Sleep(40 * time.Millisecond)
+	status = 200
+	if err := get(); err != nil {
+		t.Fatalf("half-open deneme isteği geçmeliydi: %v", err)
+	}
+	if st := tr.statuses()[0]; st.State != BreakerClosed || st.OpenedAt != nil {
+		t.Fatalf("breaker kapanmadı: %+v", st)
+	}
+}
+
+func TestBreakerIsPerHost(t *testing.T) {
+	next := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Host == "kotu.test" {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return &http.Response{StatusCode: 200, Body: http.NoBody}, nil
+	})
+	tr := newBreakerTransport(next, BreakerSettings{FailureThreshold: 1, OpenTimeout: time.Minute})
+
+	bad, _ := http.NewRequest(http.MethodGet, "http://kotu.test/", nil)
+	tr.RoundTrip(bad)
+	if _, err := tr.RoundTrip(bad); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("kotu.test için breaker açık olmalı: %v", err)
+	}
+	good, _ := http.NewRequest(http.MethodGet, "http://iyi.test/", nil)
+	if _, err := tr.RoundTrip(good); err != nil {
+		t.Fatalf("diğer host etkilenmemeli: %v", err)
+	}
+}
+
+func TestBreakerIgnoresCallerCancel(t *testing.T) {
+	next := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return nil, req.Context().Err()
+	})
+	tr := newBreakerTransport(next, BreakerSettings{FailureThreshold: 1, OpenTimeout: time.Minute})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for i := 0; i < 3; i++ {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://api.test/", nil)
+		if _, err := tr.RoundTrip(req); errors.Is(err, ErrCircuitOpen) {
+			t.Fatal("iptal edilen istekler breaker'ı açmamalı")
+		}
+	}
+}
+
+type closeTracker struct {
+	io.Reader
+	closed bool
+}
+
+func (c *closeTracker) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestBreakerClosesBodyWhenOpen(t *testing.T) {
+	next := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: 500, Body: http.NoBody}, nil
+	})
+	tr := newBreakerTransport(next, BreakerSettings{FailureThreshold: 1, OpenTimeout: time.Minute})
+	first, _ := http.NewRequest(http.MethodGet, "http://api.test/", nil)
+	tr.RoundTrip(first)
+
+	body := &closeTracker{Reader: strings.NewReader("x")}
+	req, _ := http.NewRequest(http.MethodPost, "http://api.test/", body)
+	if _, err := tr.RoundTrip(req); !errors.Is(err, ErrCircuitOpen) || !body.closed {
+		t.Fatalf("reddedilen isteğin body'i kapatılmalı: err=%v closed=%v", err, body.closed)
+	}
+}
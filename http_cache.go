@@ -0,0 +1,369 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/hashicorp/golang-lru/v2/expirable"
+	"golang.org/x/sync/singleflight"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ResponseCacheOptions outbound GET cevaplarının redis'te nasıl tutulacağı
+type ResponseCacheOptions struct {
+	// Prefix redis key'lerinin başı. default "httpcache:"
+	Prefix string
+	// DefaultTTL cevapta Cache-Control/Expires yoksa kullanılır. 0 ise bu cevaplar cache'lenmez
+	DefaultTTL time.Duration
+	// StaleTTL süresi dolan cevap revalidation için redis'te bu kadar daha tutulur. default 1 saat
+	StaleTTL time.Duration
+	// MaxBodySize bundan büyük cevaplar cache'lenmez. default 1MB
+	MaxBodySize int64
+	// FetchTimeout paylaşılan isteğin süresi. istek ilk çağıranın ctx'inden bağımsız atılır. default 30s
+	FetchTimeout time.Duration
+}
+
+type cachedResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Status     string      `json:"status"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+	ExpiresAt  time.Time   `json:"expiresAt"`
+}
+
+func (e *cachedResponse) fresh() bool {
+	return time.Now().Before(e.ExpiresAt)
+}
+
+func (e *cachedResponse) revalidatable() bool {
+	return e.Header.Get("ETag") != "" || e.Header.Get("Last-Modified") != ""
+}
+
+func (e *cachedResponse) response(req *http.Request, state string) *http.Response {
+	header := e.Header.Clone()
+	header.Set("X-Cache", state)
+	return &http.Response{
+		Status:        e.Status,
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+// keyHeaders cache key'ine giren istek header'ları. cevabın Vary'si bunların dışında bir header
+// sayıyorsa cevap saklanmaz
+var keyHeaders = []string{"Authorization", "Accept", "Accept-Encoding", "Accept-Language"}
+
+// bypassTTL paylaşılamayan cevap alınan key'e gelen istekler bu süre boyunca flight'ı beklemeden
+// doğrudan karşıya gider
+const bypassTTL = time.Minute
+
+type flightResult struct {
+	owner *int
+	entry *cachedResponse
+	state string
+	// uncacheable cevap header'ları gereği hiç saklanamaz (no-store, private, Vary, büyük body)
+	uncacheable bool
+	// res entry oluşturulamadığında (büyük body, 2xx olmayan vs) sadece owner'a dönen ham cevap
+	res *http.Response
+	// cancel res'in bağlı olduğu ctx'i bitirir. res kapatılınca da çağrılır
+	cancel context.CancelFunc
+}
+
+type responseCache struct {
+	next   http.RoundTripper
+	opts   ResponseCacheOptions
+	group  singleflight.Group
+	bypass *expirable.LRU[string, struct{}]
+}
+
+// ResponseCache GET cevaplarını Cache-Control/Expires'a göre redis'te saklar.
+// süresi dolan cevaplar ETag/Last-Modified ile revalidate edilir, aynı anda gelen
+// aynı istekler tek bir istek olarak karşıya gider. isteğin kendi Cache-Control'ü de dikkate alınır,
+// no-store cache'e hiç uğramaz, no-cache taze cevabı da karşıya sorar
+//
+//	kurClient := utils.NewClient(10*time.Second, utils.ResponseCache(utils.ResponseCacheOptions{DefaultTTL: time.Minute}))
+func ResponseCache(opts ResponseCacheOptions) Interceptor {
+	if opts.Prefix == "" {
+		opts.Prefix = "httpcache:"
+	}
+	if opts.StaleTTL <= 0 {
+		opts.StaleTTL = time.Hour
+	}
+	if opts.MaxBodySize <= 0 {
+		opts.MaxBodySize = 1 << 20
+	}
+	if opts.FetchTimeout <= 0 {
+		opts.FetchTimeout = 30 * time.Second
+	}
+	return func(next http.RoundTripper) http.RoundTripper {
+		return &responseCache{next: next, opts: opts, bypass: expirable.NewLRU[string, struct{}](1024, nil, bypassTTL)}
+	}
+}
+
+func (c *responseCache) key(req *http.Request) string {
+	h := sha256.New()
+	io.WriteString(h, req.URL.String())
+	// kullanıcıya özel cevaplar birbirine karışmasın
+	for _, name := range keyHeaders {
+		io.WriteString(h, "\n"+strings.Join(req.Header.Values(name), ","))
+	}
+	return c.opts.Prefix + hex.EncodeToString(h.Sum(nil))
+}
+
+func (c *responseCache) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqCC := strings.ToLower(req.Header.Get("Cache-Control"))
+	if req.Method != http.MethodGet || strings.Contains(reqCC, "no-store") {
+		return c.next.RoundTrip(req)
+	}
+
+	key := c.key(req)
+	if _, ok := c.bypass.Get(key); ok {
+		return c.next.RoundTrip(req)
+	}
+	// no-cache isteyen taze cevabı da karşıya sorar, taze cevap dönebilecek flight'a katılmaz
+	revalidate := strings.Contains(reqCC, "no-cache") || strings.Contains(reqCC, "max-age=0") ||
+		strings.Contains(strings.ToLower(req.Header.Get("Pragma")), "no-cache")
+	flight := key
+	if revalidate {
+		flight += "|revalidate"
+	}
+
+	mine := new(int)
+	ch := c.group.DoChan(flight, func() (interface{}, error) {
+		r, err := c.fetch(req, key, revalidate)
+		if r != nil {
+			r.owner = mine
+			if r.uncacheable {
+				c.bypass.Add(key, struct{}{})
+			}
+		}
+		return r, err
+	})
+
+	ctx := req.Context()
+	var result singleflight.Result
+	select {
+	case result = <-ch:
+	case <-ctx.Done():
+		// paylaşılan istek diğerleri için devam eder. ham cevap bize aitse kimse okumayacak
+		go func() {
+			if result := <-ch; result.Err == nil {
+				if r := result.Val.(*flightResult); r.owner == mine && r.res != nil {
+					r.res.Body.Close()
+				}
+			}
+		}()
+		return nil, ctx.Err()
+	}
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	r := result.Val.(*flightResult)
+	if r.entry != nil {
+		return r.entry.response(req, r.state), nil
+	}
+	if r.owner == mine {
+		// ham cevabı sadece biz okuyoruz, iptalimiz body'i de kessin. body kapanınca kayıt kalkar
+		r.res.Body.(*cancelBody).stop = context.AfterFunc(ctx, r.cancel)
+		return r.res, nil
+	}
+	// cevap paylaşılamadı, kendi isteğimizi atıyoruz. bekleyenler bunu aynı anda yapar,
+	// saklanamayan key'e sonradan gelenler bypass sayesinde hiç beklemez
+	return c.next.RoundTrip(req)
+}
+
+// cancelBody kapatılınca fetch'in ctx'ini de bitirir
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+	// stop owner'ın ctx'ine bağlanan AfterFunc'ı kaldırır
+	stop func() bool
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	if b.stop != nil {
+		b.stop()
+	}
+	return err
+}
+
+// fetch bekleyen herkes için çalışır, bu yüzden ilk çağıranın iptali diğerlerini düşürmesin diye
+// ctx'in sadece değerlerini (trace vs) alır
+func (c *responseCache) fetch(req *http.Request, key string, revalidate bool) (fr *flightResult, err error) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(req.Context()), c.opts.FetchTimeout)
+	defer func() {
+		if fr != nil && fr.res != nil {
+			fr.res.Body = &cancelBody{ReadCloser: fr.res.Body, cancel: cancel}
+			fr.cancel = cancel
+			return
+		}
+		cancel()
+	}()
+
+	cached := c.load(ctx, key)
+	if cached != nil && cached.fresh() && !revalidate {
+		return &flightResult{entry: cached, state: "HIT"}, nil
+	}
+
+	out := req.WithContext(ctx)
+	if cached != nil && cached.revalidatable() {
+		out = out.Clone(ctx)
+		if etag := cached.Header.Get("ETag"); etag != "" {
+			out.Header.Set("If-None-Match", etag)
+		}
+		if lm := cached.Header.Get("Last-Modified"); lm != "" {
+			out.Header.Set("If-Modified-Since", lm)
+		}
+	}
+
+	res, err := c.next.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode == http.StatusNotModified && cached != nil {
+		res.Body.Close()
+		for k, vs := range res.Header {
+			cached.Header[k] = vs
+		}
+		ttl, _ := c.ttl(res.Header)
+		cached.ExpiresAt = time.Now().Add(ttl)
+		c.store(ctx, key, cached, ttl)
+		return &flightResult{entry: cached, state: "REVALIDATED"}, nil
+	}
+
+	ttl, cacheable := c.ttl(res.Header)
+	if res.StatusCode != http.StatusOK {
+		return &flightResult{res: res}, nil
+	}
+	if !cacheable {
+		return &flightResult{res: res, uncacheable: true}, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, c.opts.MaxBodySize+1))
+	if err != nil {
+		res.Body.Close()
+		return nil, err
+	}
+	if int64(len(body)) > c.opts.MaxBodySize {
+		// okuduğumuz kısmı geri koyup cevabı stream olarak dönüyoruz
+		res.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), res.Body), res.Body}
+		return &flightResult{res: res, uncacheable: true}, nil
+	}
+	res.Body.Close()
+
+	entry := &cachedResponse{
+		StatusCode: res.StatusCode,
+		Status:     res.Status,
+		Header:     res.Header,
+		Body:       body,
+		ExpiresAt:  time.Now().Add(ttl),
+	}
+	c.store(ctx, key, entry, ttl)
+	return &flightResult{entry: entry, state: "MISS"}, nil
+}
+
+// ttl cevabın ne kadar taze kalacağı. ikinci değer cevabın hiç saklanıp saklanamayacağı
+func (c *responseCache) ttl(header http.Header) (time.Duration, bool) {
+	cc := strings.ToLower(header.Get("Cache-Control"))
+	if strings.Contains(cc, "no-store") || strings.Contains(cc, "private") || !varyCovered(header) {
+		return 0, false
+	}
+	validators := header.Get("ETag") != "" || header.Get("Last-Modified") != ""
+	if strings.Contains(cc, "no-cache") {
+		return 0, validators
+	}
+
+	for _, directive := range strings.Split(cc, ",") {
+		directive = strings.TrimSpace(directive)
+		for _, name := range []string{"s-maxage=", "max-age="} {
+			if strings.HasPrefix(directive, name) {
+				if sec, err := strconv.Atoi(strings.TrimPrefix(directive, name)); err == nil {
+					return time.Duration(sec) * time.Second, sec > 0 || validators
+				}
+			}
+		}
+	}
+
+	if exp := header.Get("Expires"); exp != "" {
+		expires, err := http.ParseTime(exp)
+		if err != nil {
+			return 0, validators
+		}
+		now := time.Now()
+		if date, err := http.ParseTime(header.Get("Date")); err == nil {
+			now = date
+		}
+		ttl := expires.Sub(now)
+		return ttl, ttl > 0 || validators
+	}
+
+	if c.opts.DefaultTTL > 0 {
+		return c.opts.DefaultTTL, true
+	}
+	return 0, validators
+}
+
+// varyCovered cevabın Vary'deki header'ların hepsi key'e giriyorsa true. Vary: * hiçbir zaman
+func varyCovered(header http.Header) bool {
+	for _, v := range header.Values("Vary") {
+		for _, name := range strings.Split(v, ",") {
+			name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+			if name == "" {
+				continue
+			}
+			covered := false
+			for _, k := range keyHeaders {
+				if name == k {
+					covered = true
+					break
+				}
+			}
+			if !covered {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (c *responseCache) load(ctx context.Context, key string) *cachedResponse {
+	data, err := cache.Get(ctx, key)
+	if err != nil {
+		return nil
+	}
+	entry := new(cachedResponse)
+	if err := json.Unmarshal([]byte(data), entry); err != nil {
+		return nil
+	}
+	return entry
+}
+
+func (c *responseCache) store(ctx context.Context, key string, entry *cachedResponse, ttl time.Duration) {
+	if ttl < 0 {
+		ttl = 0
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	// redis'e yazamazsak cache'siz devam ediyoruz
+	cache.Set(ctx, key, data, ttl+c.opts.StaleTTL)
+}
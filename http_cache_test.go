@@ -0,0 +1,158 @@
+package utils
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func cachedGet(t *testing.T, c *http.Client, url string, header ...string) (string, string) {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	res, err := c.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	body, _ := io.ReadAll(res.Body)
+	return string(body), res.Header.Get("X-Cache")
+}
+
+func TestResponseCacheHitAndRevalidate(t *testing.T) {
+	cache.Use(cache.NewMemoryBackend())
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Cache-Control", "max-age=0")
+		w.Write([]byte("kur"))
+	}))
+	defer srv.Close()
+	c := NewClient(5*time.Second, ResponseCache(ResponseCacheOptions{}))
+
+	if body, state := cachedGet(t, c, srv.URL); body != "kur" || state != "MISS" {
+		t.Fatalf("%q %q", body, state)
+	}
+	if body, state := cachedGet(t, c, srv.URL); body != "kur" || state != "REVALIDATED" {
+		t.Fatalf("%q %q", body, state)
+	}
+	if hits.Load() != 2 {
+		t.Fatalf("%d istek", hits.Load())
+	}
+}
+
+func TestResponseCacheRequestNoCache(t *testing.T) {
+	cache.Use(cache.NewMemoryBackend())
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Write([]byte("x"))
+	}))
+	defer srv.Close()
+	c := NewClient(5*time.Second, ResponseCache(ResponseCacheOptions{}))
+
+	cachedGet(t, c, srv.URL)
+	if _, state := cachedGet(t, c, srv.URL); state != "HIT" {
+		t.Fatalf("HIT beklendi: %q", state)
+	}
+	if _, state := cachedGet(t, c, srv.URL, "Cache-Control", "no-cache"); state == "HIT" || hits.Load() != 2 {
+		t.Fatalf("no-cache karşıya gitmeli: %q, %d istek", state, hits.Load())
+	}
+	if _, state := cachedGet(t, c, srv.URL, "Cache-Control", "no-store"); state != "" || hits.Load() != 3 {
+		t.Fatalf("no-store cache'e uğramamalı: %q, %d istek", state, hits.Load())
+	}
+}
+
+func TestResponseCacheVary(t *testing.T) {
+	cache.Use(cache.NewMemoryBackend())
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Header().Set("Vary", r.URL.Query().Get("vary"))
+		w.Write([]byte(r.Header.Get("X-Tenant") + r.Header.Get("Accept-Language")))
+	}))
+	defer srv.Close()
+	c := NewClient(5*time.Second, ResponseCache(ResponseCacheOptions{}))
+
+	// key'e girmeyen header'a göre değişen cevap saklanmaz
+	cachedGet(t, c, srv.URL+"?vary=X-Tenant", "X-Tenant", "a")
+	if body, _ := cachedGet(t, c, srv.URL+"?vary=X-Tenant", "X-Tenant", "b"); body != "b" {
+		t.Fatalf("başka tenant'ın cevabı döndü: %q", body)
+	}
+	// key'e giren header'lar ayrı ayrı saklanır
+	cachedGet(t, c, srv.URL+"?vary=Accept-Language", "Accept-Language", "tr")
+	if body, state := cachedGet(t, c, srv.URL+"?vary=Accept-Language", "Accept-Language", "en"); body != "en" || state != "MISS" {
+		t.Fatalf("%q %q", body, state)
+	}
+	if body, state := cachedGet(t, c, srv.URL+"?vary=Accept-Language", "Accept-Language", "tr"); body != "tr" || state != "HIT" {
+		t.Fatalf("%q %q", body, state)
+	}
+}
+
+func TestResponseCacheCoalesces(t *testing.T) {
+	cache.Use(cache.NewMemoryBackend())
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		time.Sleep(50 * time.Millisecond)
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Write([]byte("x"))
+	}))
+	defer srv.Close()
+	c := NewClient(5*time.Second, ResponseCache(ResponseCacheOptions{}))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if res, err := c.Get(srv.URL); err == nil {
+				io.Copy(io.Discard, res.Body)
+				res.Body.Close()
+			}
+		}()
+	}
+	wg.Wait()
+	if hits.Load() != 1 {
+		t.Fatalf("aynı anda gelen istekler tek istek olmalı, %d gitti", hits.Load())
+	}
+}
+
+func TestResponseCacheOwnerCancelDoesNotFailWaiters(t *testing.T) {
+	cache.Use(cache.NewMemoryBackend())
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Write([]byte("x"))
+	}))
+	defer srv.Close()
+	c := NewClient(5*time.Second, ResponseCache(ResponseCacheOptions{}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	errc := make(chan error, 1)
+	go func() {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+		_, err := c.Do(req)
+		errc <- err
+	}()
+	time.Sleep(5 * time.Millisecond)
+	if body, _ := cachedGet(t, c, srv.URL); body != "x" {
+		t.Fatalf("%q", body)
+	}
+	if err := <-errc; err == nil {
+		t.Fatal("iptal edilen çağıran hata almalı")
+	}
+}
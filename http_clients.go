@@ -0,0 +1,297 @@
+package utils
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultClientName config'de bu isimle bir client tanımlanırsa HttpGet/HttpPost onu kullanır
+const DefaultClientName = "default"
+
+// ErrClientNotFound config'de tanımlanmamış bir client istendiğinde
+var ErrClientNotFound = errors.New("http client bulunamadı")
+
+// Client config'den oluşturulmuş isimli http client
+//
+//	partner, err := utils.GetClient("partner")
+//	err = partner.NewRequest(http.MethodGet, "/kurlar").Decode(ctx, &kurlar)
+type Client struct {
+	Name    string
+	BaseURL string
+	HTTP    *http.Client
+
+	breakers *breakerTransport
+}
+
+var (
+	clientsMu     sync.RWMutex
+	clients       = map[string]*Client{}
+	defaultClient *Client
+)
+
+var defaultClientConfig = config.HttpClientConfig{
+	Timeout:             30,
+	DialTimeout:         5,
+	TLSHandshakeTimeout: 10,
+	IdleConnTimeout:     90,
+	MaxIdleConns:        100,
+	MaxIdleConnsPerHost: 10,
+	MaxConnsPerHost:     100,
+	MaxResponseSize:     10 << 20,
+}
+
+func withDefaults(cfg config.HttpClientConfig) config.HttpClientConfig {
+	d := defaultClientConfig
+	if cfg.Timeout == 0 {
+		cfg.Timeout = d.Timeout
+	}
+	if cfg.DialTimeout <= 0 {
+		cfg.DialTimeout = d.DialTimeout
+	}
+	if cfg.TLSHandshakeTimeout <= 0 {
+		cfg.TLSHandshakeTimeout = d.TLSHandshakeTimeout
+	}
+	if cfg.IdleConnTimeout <= 0 {
+		cfg.IdleConnTimeout = d.IdleConnTimeout
+	}
+	if cfg.MaxIdleConns <= 0 {
+		cfg.MaxIdleConns = d.MaxIdleConns
+	}
+	if cfg.MaxIdleConnsPerHost <= 0 {
+		cfg.MaxIdleConnsPerHost = d.MaxIdleConnsPerHost
+	}
+	if cfg.MaxConnsPerHost <= 0 {
+		cfg.MaxConnsPerHost = d.MaxConnsPerHost
+	}
+	if cfg.MaxResponseSize == 0 {
+		cfg.MaxResponseSize = d.MaxResponseSize
+	}
+	return cfg
+}
+
+func buildTransport(cfg config.HttpClientConfig) (*http.Transport, error) {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	dialer := &net.Dialer{
+		Timeout:   time.Duration(cfg.DialTimeout) * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+	t.DialContext = dialer.DialContext
+	t.TLSHandshakeTimeout = time.Duration(cfg.TLSHandshakeTimeout) * time.Second
+	t.IdleConnTimeout = time.Duration(cfg.IdleConnTimeout) * time.Second
+	t.MaxIdleConns = cfg.MaxIdleConns
+	t.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+	t.MaxConnsPerHost = cfg.MaxConnsPerHost
+
+	if cfg.Proxy != "" {
+		proxyURL, err := url.Parse(cfg.Proxy)
+		if err != nil {
+			return nil, errors.New("proxy adresi hatalı: " + err.Error())
+		}
+		t.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, errors.New("CA dosyası okunamadı: " + err.Error())
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("CA dosyasında sertifika bulunamadı: " + cfg.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, errors.New("client sertifikası yüklenemedi: " + err.Error())
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	t.TLSClientConfig = tlsConfig
+	return t, nil
+}
+
+// NewClientFromConfig config'e göre yeni bir Client oluşturur. verilen interceptor'lar
+// propagation ve default header'lardan sonra, retry'dan önce çalışır
+func NewClientFromConfig(name string, cfg config.HttpClientConfig, interceptors ...Interceptor) (*Client, error) {
+	cfg = withDefaults(cfg)
+	t, err := buildTransport(cfg)
+	if err != nil {
+		return nil, errors.New(name + ": " + err.Error())
+	}
+
+	header := http.Header{}
+	for k, v := range cfg.Headers {
+		header.Set(k, v)
+	}
+
+	breakers := newBreakerTransport(t, DefaultBreakerSettings)
+	chain := []Interceptor{Trace(), Metrics(), Propagate(), InjectHeaders(header)}
+	if cfg.TokenURL != "" {
+		chain = append(chain, OAuth2(NewClientCredentialsSource(ClientCredentialsOptions{
+			TokenURL:     cfg.TokenURL,
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			Scopes:       cfg.Scopes,
+		})))
+	}
+	chain = append(chain, interceptors...)
+	chain = append(chain, func(next http.RoundTripper) http.RoundTripper {
+		return &retryTransport{next: next}
+	})
+	// her deneme ayrı istek sayılsın diye retry'ın içinde
+	if cfg.RateLimit > 0 {
+		var limiter Limiter = NewLocalLimiter(cfg.RateLimit, cfg.RateBurst)
+		if cfg.SharedRateLimit {
+			limiter = NewRedisLimiter(cfg.RateLimit, cfg.RateBurst)
+		}
+		chain = append(chain, RateLimit(limiter, FixedKey(name)))
+	}
+	// limiter beklemesinden sonra imzalansın ki timestamp güncel olsun
+	if cfg.SigningSecret != "" {
+		chain = append(chain, SignRequest(SigningOptions{
+			Secret:          cfg.SigningSecret,
+			SignatureHeader: cfg.SignatureHeader,
+			TimestampHeader: cfg.TimestampHeader,
+		}))
+	}
+	chain = append(chain, LimitResponse(cfg.MaxResponseSize))
+
+	// negatif timeout: süreyi sadece ctx belirler
+	var timeout time.Duration
+	if cfg.Timeout > 0 {
+		timeout = time.Duration(cfg.Timeout) * time.Second
+	}
+	return &Client{
+		Name:    name,
+		BaseURL: strings.TrimRight(cfg.BaseURL, "/"),
+		HTTP: &http.Client{
+			Timeout:   timeout,
+			Transport: Chain(breakers, chain...),
+		},
+		breakers: breakers,
+	}, nil
+}
+
+// SetupClients config'deki client'ları oluşturup kaydeder. uygulama açılırken bir kere çağrılmalı
+//
+//	if err := utils.SetupClients(config.Get().HttpClients); err != nil {
+//		log.Fatal(err)
+//	}
+func SetupClients(cfgs map[string]config.HttpClientConfig, interceptors ...Interceptor) error {
+	created := map[string]*Client{}
+	for name, cfg := range cfgs {
+		c, err := NewClientFromConfig(name, cfg, interceptors...)
+		if err != nil {
+			return err
+		}
+		created[name] = c
+	}
+
+	clientsMu.Lock()
+	defer clientsMu.Unlock()
+	for name, c := range created {
+		clients[name] = c
+	}
+	if c, ok := created[DefaultClientName]; ok {
+		defaultClient = c
+	}
+	return nil
+}
+
+// RegisterClient elle oluşturulmuş bir client'ı kaydeder. testlerde mock transport vermek için de kullanılır
+func RegisterClient(c *Client) {
+	clientsMu.Lock()
+	defer clientsMu.Unlock()
+	clients[c.Name] = c
+	if c.Name == DefaultClientName {
+		defaultClient = c
+	}
+}
+
+// GetClient isimle kayıtlı client'ı döner
+func GetClient(name string) (*Client, error) {
+	clientsMu.RLock()
+	defer clientsMu.RUnlock()
+	c, ok := clients[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrClientNotFound, name)
+	}
+	return c, nil
+}
+
+// DefaultClient HttpGet/HttpPost ve builder'ın kullandığı client.
+// config'de "default" tanımlı değilse default ayarlarla oluşturulur
+func DefaultClient() *Client {
+	clientsMu.RLock()
+	c := defaultClient
+	clientsMu.RUnlock()
+	if c != nil {
+		return c
+	}
+
+	clientsMu.Lock()
+	defer clientsMu.Unlock()
+	if defaultClient == nil {
+		// default ayarlarda dosya okunmadığı için hata dönmez
+		defaultClient, _ = NewClientFromConfig(DefaultClientName, defaultClientConfig)
+	}
+	return defaultClient
+}
+
+// NewRequest BaseURL'e göre bir istek hazırlar. path tam bir url ise olduğu gibi kullanılır
+func (c *Client) NewRequest(method, path string) *Request {
+	target := path
+	if c.BaseURL != "" && !strings.Contains(path, "://") {
+		target = c.BaseURL + "/" + strings.TrimLeft(path, "/")
+	}
+	return NewRequest(method, target).Client(c.HTTP)
+}
+
+// BreakerStatuses bu client'ın host bazında breaker durumları
+func (c *Client) BreakerStatuses() []BreakerStatus {
+	if c.breakers == nil {
+		return nil
+	}
+	return c.breakers.statuses()
+}
+
+// BreakerStatuses kayıtlı tüm client'ların breaker durumları. health endpoint'inde dönülebilir
+func BreakerStatuses() []BreakerStatus {
+	list := []*Client{DefaultClient()}
+	clientsMu.RLock()
+	for _, c := range clients {
+		if c != list[0] {
+			list = append(list, c)
+		}
+	}
+	clientsMu.RUnlock()
+
+	var result []BreakerStatus
+	for _, c := range list {
+		for _, st := range c.BreakerStatuses() {
+			st.Client = c.Name
+			result = append(result, st)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Client < result[j].Client })
+	return result
+}
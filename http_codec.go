@@ -0,0 +1,288 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"golang.org/x/net/html/charset"
+	"google.golang.org/protobuf/proto"
+	"mime"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Codec bir content type için body'i encode/decode eder
+type Codec interface {
+	ContentType() string
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+var (
+	JSONCodec     Codec = jsonCodec{}
+	XMLCodec      Codec = xmlCodec{}
+	FormCodec     Codec = formCodec{}
+	TextCodec     Codec = textCodec{}
+	ProtobufCodec Codec = protobufCodec{}
+)
+
+var (
+	codecsMu sync.RWMutex
+	codecs   = map[string]Codec{
+		"application/json":                  JSONCodec,
+		"text/json":                         JSONCodec,
+		"application/xml":                   XMLCodec,
+		"text/xml":                          XMLCodec,
+		"application/x-www-form-urlencoded": FormCodec,
+		"text/plain":                        TextCodec,
+		"text/html":                         TextCodec,
+		"application/x-protobuf":            ProtobufCodec,
+		"application/protobuf":              ProtobufCodec,
+	}
+)
+
+// RegisterCodec bir media type için codec ekler veya değiştirir, örn. "application/vnd.partner+json"
+func RegisterCodec(mediaType string, c Codec) {
+	codecsMu.Lock()
+	codecs[strings.ToLower(mediaType)] = c
+	codecsMu.Unlock()
+}
+
+// CodecFor Content-Type header'ına göre codec seçer. +json ve +xml sonekleri de tanınır.
+// header yoksa veya tanınmıyorsa json kullanılır
+func CodecFor(contentType string) Codec {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return JSONCodec
+	}
+	codecsMu.RLock()
+	c, ok := codecs[mediaType]
+	codecsMu.RUnlock()
+	if ok {
+		return c
+	}
+	switch {
+	case strings.HasSuffix(mediaType, "+json"):
+		return JSONCodec
+	case strings.HasSuffix(mediaType, "+xml"):
+		return XMLCodec
+	}
+	return JSONCodec
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) ContentType() string { return mimeJSON }
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) { return json.Marshal(v) }
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+
+type xmlCodec struct{}
+
+func (xmlCodec) ContentType() string { return "application/xml; charset=utf-8" }
+
+func (xmlCodec) Marshal(v interface{}) ([]byte, error) {
+	data, err := xml.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
+
+// Unmarshal iso-8859-9 gibi utf-8 olmayan encoding'leri de okur
+func (xmlCodec) Unmarshal(data []byte, v interface{}) error {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	d.CharsetReader = charset.NewReaderLabel
+	return d.Decode(v)
+}
+
+// formCodec url.Values, map[string]string ve `form` tag'li struct'ları destekler
+type formCodec struct{}
+
+func (formCodec) ContentType() string { return "application/x-www-form-urlencoded" }
+
+func (formCodec) Marshal(v interface{}) ([]byte, error) {
+	switch val := v.(type) {
+	case url.Values:
+		return []byte(val.Encode()), nil
+	case map[string]string:
+		q := url.Values{}
+		for k, s := range val {
+			q.Set(k, s)
+		}
+		return []byte(q.Encode()), nil
+	}
+
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("form: %T encode edilemez", v)
+	}
+	q := url.Values{}
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		name, ok := formName(rt.Field(i))
+		if !ok {
+			continue
+		}
+		f := rv.Field(i)
+		if f.Kind() == reflect.Slice {
+			for j := 0; j < f.Len(); j++ {
+				q.Add(name, fmt.Sprint(f.Index(j).Interface()))
+			}
+			continue
+		}
+		q.Set(name, fmt.Sprint(f.Interface()))
+	}
+	return []byte(q.Encode()), nil
+}
+
+func (formCodec) Unmarshal(data []byte, v interface{}) error {
+	q, err := url.ParseQuery(string(data))
+	if err != nil {
+		return err
+	}
+	switch val := v.(type) {
+	case *url.Values:
+		*val = q
+		return nil
+	case *map[string]string:
+		*val = make(map[string]string, len(q))
+		for k := range q {
+			(*val)[k] = q.Get(k)
+		}
+		return nil
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("form: %T'ye decode edilemez", v)
+	}
+	rv = rv.Elem()
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		name, ok := formName(rt.Field(i))
+		if !ok || !q.Has(name) {
+			continue
+		}
+		f := rv.Field(i)
+		if f.Kind() == reflect.Slice {
+			vals := q[name]
+			s := reflect.MakeSlice(f.Type(), len(vals), len(vals))
+			for j, raw := range vals {
+				if err := setFormValue(s.Index(j), raw); err != nil {
+					return fmt.Errorf("form: %s: %w", name, err)
+				}
+			}
+			f.Set(s)
+			continue
+		}
+		if err := setFormValue(f, q.Get(name)); err != nil {
+			return fmt.Errorf("form: %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func formName(f reflect.StructField) (string, bool) {
+	if f.PkgPath != "" {
+		return "", false
+	}
+	name := strings.Split(f.Tag.Get("form"), ",")[0]
+	if name == "-" {
+		return "", false
+	}
+	if name == "" {
+		name = f.Name
+	}
+	return name, true
+}
+
+func setFormValue(f reflect.Value, raw string) error {
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		f.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(raw, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetFloat(n)
+	default:
+		return fmt.Errorf("%s tipi desteklenmiyor", f.Type())
+	}
+	return nil
+}
+
+// textCodec *string ve *[]byte'a okur, string, []byte ve fmt.Stringer yazar.
+// başka bir tipe okunurken json denenir, json'u text/plain diye dönen servisler çok
+type textCodec struct{}
+
+func (textCodec) ContentType() string { return "text/plain; charset=utf-8" }
+
+func (textCodec) Marshal(v interface{}) ([]byte, error) {
+	switch val := v.(type) {
+	case string:
+		return []byte(val), nil
+	case []byte:
+		return val, nil
+	case fmt.Stringer:
+		return []byte(val.String()), nil
+	}
+	return nil, fmt.Errorf("text: %T encode edilemez", v)
+}
+
+func (textCodec) Unmarshal(data []byte, v interface{}) error {
+	switch val := v.(type) {
+	case *string:
+		*val = string(data)
+	case *[]byte:
+		*val = append((*val)[:0], data...)
+	default:
+		return json.Unmarshal(data, v)
+	}
+	return nil
+}
+
+type protobufCodec struct{}
+
+func (protobufCodec) ContentType() string { return "application/x-protobuf" }
+
+func (protobufCodec) Marshal(v interface{}) ([]byte, error) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("protobuf: %T proto.Message değil", v)
+	}
+	return proto.Marshal(m)
+}
+
+func (protobufCodec) Unmarshal(data []byte, v interface{}) error {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return errors.New("protobuf: decode edilecek model proto.Message olmalı")
+	}
+	return proto.Unmarshal(data, m)
+}
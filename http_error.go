@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"unicode/utf8"
+)
+
+// maxErrorBody hata cevaplarından saklanacak en fazla byte
+const maxErrorBody = 4 << 10
+
+// HTTPError 2xx dışında dönen cevaplar için. errors.As ile yakalanabilir
+//
+//	var httpErr *utils.HTTPError
+//	if errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusConflict {
+//		...
+//	}
+type HTTPError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Status     string
+	Header     http.Header
+	// Body cevabın ilk 4KB'ı
+	Body []byte
+	// Truncated body 4KB'dan uzunsa true
+	Truncated bool
+}
+
+func newHTTPError(res *http.Response) *HTTPError {
+	e := &HTTPError{
+		StatusCode: res.StatusCode,
+		Status:     res.Status,
+		Header:     res.Header,
+	}
+	if res.Request != nil {
+		e.Method = res.Request.Method
+		e.URL = res.Request.URL.Redacted()
+	}
+	body, _ := io.ReadAll(io.LimitReader(res.Body, maxErrorBody+1))
+	if len(body) > maxErrorBody {
+		body = body[:maxErrorBody]
+		e.Truncated = true
+	}
+	e.Body = body
+	return e
+}
+
+func (e *HTTPError) Error() string {
+	msg := e.Method + " " + e.URL + ": status code: " + e.Status
+	if e.Status == "" {
+		msg += strconv.Itoa(e.StatusCode)
+	}
+	if excerpt := e.excerpt(200); excerpt != "" {
+		msg += ": " + excerpt
+	}
+	return msg
+}
+
+func (e *HTTPError) excerpt(n int) string {
+	b := e.Body
+	if len(b) > n {
+		b = b[:n]
+		for len(b) > 0 && !utf8.Valid(b) {
+			b = b[:len(b)-1]
+		}
+		return string(b) + "..."
+	}
+	return string(b)
+}
+
+// DecodeJSON hata body'sini partner'ın hata modeline decode eder
+func (e *HTTPError) DecodeJSON(v interface{}) error {
+	if e.Truncated {
+		return errors.New("hata body'si kesildiği için decode edilemedi")
+	}
+	return json.Unmarshal(e.Body, v)
+}
+
+// IsHTTPStatus err bir HTTPError ve verilen status'a sahipse true döner
+func IsHTTPStatus(err error, code int) bool {
+	var httpErr *HTTPError
+	return errors.As(err, &httpErr) && httpErr.StatusCode == code
+}
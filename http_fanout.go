@@ -0,0 +1,169 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FanOutMode bir çağrı hata verdiğinde diğerlerine ne olacağı
+type FanOutMode int
+
+const (
+	// CollectAll tüm çağrılar biter, hatalar toplanır
+	CollectAll FanOutMode = iota
+	// FailFast ilk hatada kalan çağrılar iptal edilir
+	FailFast
+)
+
+// FanOutOptions zero değerler default'a döner
+type FanOutOptions struct {
+	// Concurrency aynı anda en fazla kaç çağrı çalışır, default 8
+	Concurrency int
+	// Timeout her çağrı için ayrı süre sınırı, 0 ise sadece üst ctx geçerli
+	Timeout time.Duration
+	Mode    FanOutMode
+}
+
+// FanOutCall isim hata mesajında hangi çağrının patladığını göstermek için
+type FanOutCall[T any] struct {
+	Name string
+	Do   func(ctx context.Context) (T, error)
+}
+
+// CallError FanOut'taki tek bir çağrının hatası
+type CallError struct {
+	Index int
+	Name  string
+	Err   error
+}
+
+func (e *CallError) Error() string {
+	if e.Name != "" {
+		return fmt.Sprintf("[%d] %s: %v", e.Index, e.Name, e.Err)
+	}
+	return fmt.Sprintf("[%d] %v", e.Index, e.Err)
+}
+
+func (e *CallError) Unwrap() error {
+	return e.Err
+}
+
+// FanOutError başarısız çağrıların listesi. errors.Is/As her bir çağrının hatasına bakar
+type FanOutError struct {
+	Total  int
+	Errors []*CallError
+}
+
+func (e *FanOutError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("%d çağrıdan %d tanesi başarısız: %s", e.Total, len(e.Errors), strings.Join(msgs, "; "))
+}
+
+func (e *FanOutError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err
+	}
+	return errs
+}
+
+// FanOut çağrıları paralel çalıştırır. sonuçlar çağrılarla aynı sıradadır, başarısız
+// olanların yerinde T'nin zero değeri durur. hata varsa *FanOutError döner
+//
+//	kurlar, err := utils.FanOut(ctx, utils.FanOutOptions{Concurrency: 4, Timeout: 2 * time.Second}, []utils.FanOutCall[Kur]{
+//		{Name: "tcmb", Do: func(ctx context.Context) (Kur, error) { return utils.Get[Kur](ctx, tcmbURL, nil) }},
+//		{Name: "banka", Do: func(ctx context.Context) (Kur, error) { return utils.Get[Kur](ctx, bankaURL, nil) }},
+//	})
+func FanOut[T any](ctx context.Context, opts FanOutOptions, calls []FanOutCall[T]) ([]T, error) {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = 8
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]T, len(calls))
+	var (
+		mu     sync.Mutex
+		errs   []*CallError
+		failed bool
+		wg     sync.WaitGroup
+	)
+	sem := make(chan struct{}, concurrency)
+
+loop:
+	for i := range calls {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			break loop
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			callCtx := ctx
+			if opts.Timeout > 0 {
+				var callCancel context.CancelFunc
+				callCtx, callCancel = context.WithTimeout(ctx, opts.Timeout)
+				defer callCancel()
+			}
+			res, err := calls[i].Do(callCtx)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err == nil {
+				results[i] = res
+				return
+			}
+			// fail fast'te iptal edilen çağrıların hataları asıl hatayı gölgelemesin
+			if failed && opts.Mode == FailFast && errors.Is(err, context.Canceled) {
+				return
+			}
+			errs = append(errs, &CallError{Index: i, Name: calls[i].Name, Err: err})
+			if opts.Mode == FailFast && !failed {
+				failed = true
+				cancel()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if len(errs) == 0 {
+		// üst ctx bittiyse başlatılamayan çağrılar olabilir
+		if err := ctx.Err(); err != nil && !failed {
+			return results, err
+		}
+		return results, nil
+	}
+	sort.Slice(errs, func(a, b int) bool { return errs[a].Index < errs[b].Index })
+	return results, &FanOutError{Total: len(calls), Errors: errs}
+}
+
+// FanOutRequests hazırlanmış istekleri paralel atıp cevapları T olarak döner
+//
+//	reqs := make([]*utils.Request, len(ids))
+//	for i, id := range ids {
+//		reqs[i] = partner.NewRequest(http.MethodGet, "/urun/"+id)
+//	}
+//	urunler, err := utils.FanOutRequests[Urun](ctx, utils.FanOutOptions{Mode: utils.FailFast}, reqs...)
+func FanOutRequests[T any](ctx context.Context, opts FanOutOptions, reqs ...*Request) ([]T, error) {
+	calls := make([]FanOutCall[T], len(reqs))
+	for i, r := range reqs {
+		r := r
+		calls[i] = FanOutCall[T]{
+			Name: r.method + " " + r.url,
+			Do:   func(ctx context.Context) (T, error) { return Send[T](ctx, r) },
+		}
+	}
+	return FanOut(ctx, opts, calls)
+}
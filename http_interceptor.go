@@ -0,0 +1,181 @@
+package utils
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"net/http"
+	"time"
+)
+
+// RoundTripperFunc bir fonksiyonu http.RoundTripper olarak kullanmak için
+type RoundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// closeRequestBody istek next'e verilmeden hata dönülürken çağrılmalı. RoundTripper sözleşmesi
+// body'i kapatmayı istiyor, kapatılmazsa Multipart'ın pipe'ına yazan goroutine ve dosya açık kalır
+func closeRequestBody(req *http.Request) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+}
+
+// Interceptor bir RoundTripper'ı sarıp araya girer. auth, log, trace vs için
+type Interceptor func(next http.RoundTripper) http.RoundTripper
+
+// Chain interceptor'ları base'in etrafına sarar. ilk verilen en dışta çalışır,
+// yani isteği ilk o görür, cevabı en son o görür
+func Chain(base http.RoundTripper, interceptors ...Interceptor) http.RoundTripper {
+	rt := base
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		rt = interceptors[i](rt)
+	}
+	return rt
+}
+
+// NewClient verilen interceptor'larla yeni bir http client oluşturur.
+// retry ve circuit breaker istenirse ayrıca verilmeli
+//
+//	partnerClient := utils.NewClient(10*time.Second,
+//		utils.Logging(logger),
+//		utils.BearerToken(utils.StaticToken(apiKey)),
+//		utils.Retry(utils.DefaultRetryPolicy),
+//		utils.CircuitBreaker(utils.DefaultBreakerSettings),
+//	)
+//	err := utils.NewRequest(http.MethodGet, url).Client(partnerClient).Decode(ctx, &model)
+func NewClient(timeout time.Duration, interceptors ...Interceptor) *http.Client {
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: Chain(newTransport(), interceptors...),
+	}
+}
+
+func newTransport() *http.Transport {
+	// default ayarlarda dosya okunmadığı için hata dönmez
+	t, _ := buildTransport(defaultClientConfig)
+	return t
+}
+
+// OnRequest istek gönderilmeden önce çalışır. hata dönerse istek gönderilmez.
+// fn'e verilen request bir kopyadır, üzerinde değişiklik yapılabilir
+func OnRequest(fn func(req *http.Request) error) Interceptor {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			req = req.Clone(req.Context())
+			if err := fn(req); err != nil {
+				closeRequestBody(req)
+				return nil, err
+			}
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+// OnResponse cevap geldikten sonra çalışır. hata dönerse body kapatılıp hata döner
+func OnResponse(fn func(res *http.Response) error) Interceptor {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			res, err := next.RoundTrip(req)
+			if err != nil {
+				return res, err
+			}
+			if err := fn(res); err != nil {
+				res.Body.Close()
+				return nil, err
+			}
+			return res, nil
+		})
+	}
+}
+
+// Retry geçici hatalarda isteği policy'ye göre tekrarlar
+func Retry(policy RetryPolicy) Interceptor {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return &retryTransport{next: next, policy: &policy}
+	}
+}
+
+// CircuitBreaker her host için ayrı bir circuit breaker uygular
+func CircuitBreaker(settings BreakerSettings) Interceptor {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return newBreakerTransport(next, settings)
+	}
+}
+
+// Logging her isteği method, url, status ve süresiyle loglar
+func Logging(logger *zap.Logger) Interceptor {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			res, err := next.RoundTrip(req)
+			fields := []zap.Field{
+				zap.String("method", req.Method),
+				zap.String("url", req.URL.Redacted()),
+				zap.Duration("duration", time.Since(start)),
+			}
+			rqID := req.Header.Get(HeaderRequestID)
+			if rqID == "" {
+				rqID = RequestIDFrom(req.Context())
+			}
+			if rqID != "" {
+				fields = append(fields, zap.String("requestid", rqID))
+			}
+			if err != nil {
+				logger.Error("outbound istek başarısız", append(fields, zap.Error(err))...)
+				return res, err
+			}
+			fields = append(fields, zap.Int("status", res.StatusCode))
+			if res.StatusCode >= 500 {
+				logger.Warn("outbound istek", fields...)
+			} else {
+				logger.Info("outbound istek", fields...)
+			}
+			return res, nil
+		})
+	}
+}
+
+// InjectHeaders verilen header'ları istekte yoksa ekler
+func InjectHeaders(header http.Header) Interceptor {
+	return OnRequest(func(req *http.Request) error {
+		for k, vs := range header {
+			if req.Header.Get(k) == "" {
+				req.Header[k] = append([]string(nil), vs...)
+			}
+		}
+		return nil
+	})
+}
+
+// TokenSource bearer token sağlayan kaynak
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// StaticToken hiç değişmeyen bir token
+type StaticToken string
+
+func (t StaticToken) Token(ctx context.Context) (string, error) {
+	return string(t), nil
+}
+
+// TokenFunc bir fonksiyonu TokenSource olarak kullanmak için
+type TokenFunc func(ctx context.Context) (string, error)
+
+func (f TokenFunc) Token(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// BearerToken her isteğe Authorization: Bearer <token> header'ı ekler
+func BearerToken(src TokenSource) Interceptor {
+	return OnRequest(func(req *http.Request) error {
+		token, err := src.Token(req.Context())
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		return nil
+	})
+}
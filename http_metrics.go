@@ -0,0 +1,23 @@
+package utils
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Metrics dış isteklerin süresini host bazında ölçer. bağlantı hatalarında status "error" olur
+func Metrics() Interceptor {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			res, err := next.RoundTrip(req)
+			status := "error"
+			if err == nil {
+				status = strconv.Itoa(res.StatusCode)
+			}
+			metrics.OutboundDuration.WithLabelValues(req.URL.Host, req.Method, status).Observe(time.Since(start).Seconds())
+			return res, err
+		})
+	}
+}
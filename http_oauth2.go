@@ -0,0 +1,240 @@
+package utils
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"golang.org/x/sync/singleflight"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RefreshableTokenSource süresi dolmadan geçersiz olan token'ların yenilenebildiği kaynak
+type RefreshableTokenSource interface {
+	TokenSource
+	// Invalidate token'ı cache'ten siler, sonraki Token çağrısı yenisini alır
+	Invalidate(ctx context.Context, token string)
+}
+
+// ClientCredentialsOptions OAuth2 client credentials ayarları
+type ClientCredentialsOptions struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	// EndpointParams token isteğine eklenecek ek parametreler, örn. audience
+	EndpointParams url.Values
+	// AuthInBody true ise client id ve secret basic auth yerine form içinde gönderilir
+	AuthInBody bool
+	// ExpiryDelta token bitmeden bu kadar önce yenilenir, default 1 dakika
+	ExpiryDelta time.Duration
+	// Client token isteklerinin gideceği client, default 30sn timeout'lu yeni bir client
+	Client *http.Client
+	// FetchTimeout paylaşılan token isteğinin süresi. istek ilk çağıranın ctx'inden bağımsız atılır. default 30s
+	FetchTimeout time.Duration
+}
+
+// ClientCredentialsSource OAuth2 client credentials token'larını alır.
+// token redis'te tüm replikalar için ortak tutulur, aynı anda gelen yenilemeler tek isteğe düşer
+//
+//	src := utils.NewClientCredentialsSource(utils.ClientCredentialsOptions{
+//		TokenURL: "https://auth.partner.com/oauth/token", ClientID: id, ClientSecret: secret,
+//	})
+//	partner := utils.NewClient(10*time.Second, utils.OAuth2(src))
+type ClientCredentialsSource struct {
+	opts  ClientCredentialsOptions
+	key   string
+	group singleflight.Group
+
+	mu    sync.RWMutex
+	token cachedToken
+}
+
+type cachedToken struct {
+	AccessToken string    `json:"accessToken"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+}
+
+func (t cachedToken) valid(delta time.Duration) bool {
+	return t.AccessToken != "" && time.Now().Add(delta).Before(t.ExpiresAt)
+}
+
+func NewClientCredentialsSource(opts ClientCredentialsOptions) *ClientCredentialsSource {
+	if opts.ExpiryDelta <= 0 {
+		opts.ExpiryDelta = time.Minute
+	}
+	if opts.FetchTimeout <= 0 {
+		opts.FetchTimeout = 30 * time.Second
+	}
+	if opts.Client == nil {
+		opts.Client = NewClient(30*time.Second, Trace())
+	}
+	h := sha256.Sum256([]byte(opts.TokenURL + "\n" + opts.ClientID + "\n" + strings.Join(opts.Scopes, " ")))
+	return &ClientCredentialsSource{opts: opts, key: "oauth2:" + hex.EncodeToString(h[:16])}
+}
+
+func (s *ClientCredentialsSource) Token(ctx context.Context) (string, error) {
+	s.mu.RLock()
+	t := s.token
+	s.mu.RUnlock()
+	if t.valid(s.opts.ExpiryDelta) {
+		return t.AccessToken, nil
+	}
+
+	// yenileme bekleyen herkes için yapılır, ilk çağıranın iptali diğerlerini düşürmesin diye
+	// ctx'in sadece değerleri (trace vs) alınır. her çağıran kendi ctx'i kadar bekler
+	ch := s.group.DoChan(s.key, func() (interface{}, error) {
+		fetchCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.opts.FetchTimeout)
+		defer cancel()
+		if t, ok := s.fromRedis(fetchCtx); ok {
+			return t, nil
+		}
+		return s.fetch(fetchCtx)
+	})
+	var res singleflight.Result
+	select {
+	case res = <-ch:
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+	if res.Err != nil {
+		return "", res.Err
+	}
+	t = res.Val.(cachedToken)
+	s.mu.Lock()
+	s.token = t
+	s.mu.Unlock()
+	return t.AccessToken, nil
+}
+
+func (s *ClientCredentialsSource) Invalidate(ctx context.Context, token string) {
+	s.mu.Lock()
+	if s.token.AccessToken == token {
+		s.token = cachedToken{}
+	}
+	s.mu.Unlock()
+	// başka bir replika yenilemiş olabilir, sadece aynı token ise silinir
+	if t, ok := s.fromRedis(ctx); ok && t.AccessToken == token {
+		cache.Delete(ctx, s.key)
+	}
+}
+
+func (s *ClientCredentialsSource) fromRedis(ctx context.Context) (cachedToken, bool) {
+	data, err := cache.Get(ctx, s.key)
+	if err != nil {
+		return cachedToken{}, false
+	}
+	var t cachedToken
+	if err := json.Unmarshal([]byte(data), &t); err != nil || !t.valid(s.opts.ExpiryDelta) {
+		return cachedToken{}, false
+	}
+	return t, true
+}
+
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+func (s *ClientCredentialsSource) fetch(ctx context.Context) (cachedToken, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(s.opts.Scopes) > 0 {
+		form.Set("scope", strings.Join(s.opts.Scopes, " "))
+	}
+	for k, vs := range s.opts.EndpointParams {
+		form[k] = append(form[k], vs...)
+	}
+	if s.opts.AuthInBody {
+		form.Set("client_id", s.opts.ClientID)
+		form.Set("client_secret", s.opts.ClientSecret)
+	}
+
+	r := NewRequest(http.MethodPost, s.opts.TokenURL).Client(s.opts.Client).Form(form)
+	if !s.opts.AuthInBody {
+		r.Header("Authorization", "Basic "+basicAuth(s.opts.ClientID, s.opts.ClientSecret))
+	}
+	var res tokenResponse
+	if err := r.Decode(ctx, &res); err != nil {
+		return cachedToken{}, fmt.Errorf("oauth2 token alınamadı: %w", err)
+	}
+	if res.AccessToken == "" {
+		return cachedToken{}, fmt.Errorf("oauth2 token alınamadı: cevapta access_token yok")
+	}
+	if res.TokenType != "" && !strings.EqualFold(res.TokenType, "bearer") {
+		return cachedToken{}, fmt.Errorf("oauth2 token tipi desteklenmiyor: %s", res.TokenType)
+	}
+	// expires_in dönmeyen sunucular için bir saat varsayılır
+	expiresIn := time.Duration(res.ExpiresIn) * time.Second
+	if expiresIn <= 0 {
+		expiresIn = time.Hour
+	}
+	t := cachedToken{AccessToken: res.AccessToken, ExpiresAt: time.Now().Add(expiresIn)}
+
+	if ttl := expiresIn - s.opts.ExpiryDelta; ttl > 0 {
+		if data, err := json.Marshal(t); err == nil {
+			cache.Set(ctx, s.key, data, ttl)
+		}
+	}
+	return t, nil
+}
+
+// basicAuth RFC 6749'a göre id ve secret önce url encode edilir
+func basicAuth(id, secret string) string {
+	return base64.StdEncoding.EncodeToString([]byte(url.QueryEscape(id) + ":" + url.QueryEscape(secret)))
+}
+
+// OAuth2 her isteğe src'den aldığı bearer token'ı ekler. cevap 401 ise token
+// geçersiz sayılır ve istek yeni token ile bir kere tekrarlanır
+func OAuth2(src RefreshableTokenSource) Interceptor {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			token, err := src.Token(req.Context())
+			if err != nil {
+				closeRequestBody(req)
+				return nil, err
+			}
+			out := req.Clone(req.Context())
+			out.Header.Set("Authorization", "Bearer "+token)
+			res, err := next.RoundTrip(out)
+			if err != nil || res.StatusCode != http.StatusUnauthorized {
+				return res, err
+			}
+
+			// body tekrar okunamıyorsa 401 olduğu gibi döner
+			var body io.ReadCloser
+			if req.Body != nil && req.Body != http.NoBody {
+				if req.GetBody == nil {
+					return res, nil
+				}
+				if body, err = req.GetBody(); err != nil {
+					return res, nil
+				}
+			}
+			src.Invalidate(req.Context(), token)
+			token, err = src.Token(req.Context())
+			if err != nil {
+				if body != nil {
+					body.Close()
+				}
+				return res, nil
+			}
+			io.Copy(io.Discard, io.LimitReader(res.Body, 4<<10))
+			res.Body.Close()
+
+			retry := req.Clone(req.Context())
+			if body != nil {
+				retry.Body = body
+			}
+			retry.Header.Set("Authorization", "Bearer "+token)
+			return next.RoundTrip(retry)
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func tokenServer(t *testing.T, delay time.Duration) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	issued := new(atomic.Int32)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, secret, _ := r.BasicAuth()
+		if id != "istemci" || secret != "gizli" || r.FormValue("grant_type") != "client_credentials" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		time.Sleep(delay)
+		n := issued.Add(1)
+		fmt.Fprintf(w, `{"access_token":"tok-%d","token_type":"Bearer","expires_in":3600}`, n)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, issued
+}
+
+func TestClientCredentialsSharesToken(t *testing.T) {
+	cache.Use(cache.NewMemoryBackend())
+	srv, issued := tokenServer(t, 20*time.Millisecond)
+	opts := ClientCredentialsOptions{TokenURL: srv.URL, ClientID: "istemci", ClientSecret: "gizli", Scopes: []string{"paylasim"}}
+	src := NewClientCredentialsSource(opts)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			src.Token(context.Background())
+		}()
+	}
+	wg.Wait()
+	if issued.Load() != 1 {
+		t.Fatalf("aynı anda gelen yenilemeler tek istek olmalı, %d gitti", issued.Load())
+	}
+	// başka replika redis'teki token'ı kullanır
+	other := NewClientCredentialsSource(opts)
+	if tok, err := other.Token(context.Background()); err != nil || tok != "tok-1" {
+		t.Fatalf("%q %v", tok, err)
+	}
+}
+
+func TestClientCredentialsFirstCallerCancel(t *testing.T) {
+	cache.Use(cache.NewMemoryBackend())
+	srv, _ := tokenServer(t, 100*time.Millisecond)
+	src := NewClientCredentialsSource(ClientCredentialsOptions{TokenURL: srv.URL, ClientID: "istemci", ClientSecret: "gizli", Scopes: []string{"iptal"}})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	first := make(chan error, 1)
+	go func() {
+		_, err := src.Token(ctx)
+		first <- err
+	}()
+	time.Sleep(5 * time.Millisecond)
+	if tok, err := src.Token(context.Background()); err != nil || tok != "tok-1" {
+		t.Fatalf("bekleyen çağıran token almalı: %q %v", tok, err)
+	}
+	if err := <-first; err == nil {
+		t.Fatal("ilk çağıran kendi ctx'iyle dönmeliydi")
+	}
+}
+
+func TestOAuth2RetriesOnceOn401(t *testing.T) {
+	cache.Use(cache.NewMemoryBackend())
+	tokens, _ := tokenServer(t, 0)
+	src := NewClientCredentialsSource(ClientCredentialsOptions{TokenURL: tokens.URL, ClientID: "istemci", ClientSecret: "gizli", Scopes: []string{"api"}})
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// ilk token sunucu tarafında iptal edilmiş
+		if r.Header.Get("Authorization") != "Bearer tok-2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte("tamam"))
+	}))
+	defer api.Close()
+
+	res, err := NewClient(5*time.Second, OAuth2(src)).Get(api.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("yeni token ile tekrar denenmeli: %d", res.StatusCode)
+	}
+}
@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"context"
+	"github.com/valyala/fasthttp"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// HeaderRequestID AppCtx.Log()'un okuduğu request id header'ı. aynı isimle karşıya gönderilir
+	HeaderRequestID = "requestid"
+	// HeaderUserID isteği başlatan kullanıcının id'si
+	HeaderUserID = "X-User-ID"
+	// HeaderRequestTimeout isteğin bitmesi gereken süreye kalan milisaniye
+	HeaderRequestTimeout = "X-Request-Timeout"
+)
+
+type requestIDKey struct{}
+type userIDKey struct{}
+
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+func RequestIDFrom(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+func WithUserID(ctx context.Context, userID int64) context.Context {
+	return context.WithValue(ctx, userIDKey{}, userID)
+}
+
+func UserIDFrom(ctx context.Context) int64 {
+	id, _ := ctx.Value(userIDKey{}).(int64)
+	return id
+}
+
+// requestScope *context.AppCtx'in burada ihtiyaç duyulan kısmı.
+// context paketi utils'i import edemediği için interface olarak alıyoruz
+type requestScope interface {
+	UserContext() context.Context
+	Context() *fasthttp.RequestCtx
+	Get(key string, defaultValue ...string) string
+	GetUserID() int64
+}
+
+// OutboundContext handler içinden karşı servislere istek atarken kullanılacak context.
+// request id ve kullanıcı id'sini taşır, deadline'ı gelen isteğin kalan süresidir.
+// default client bunları header olarak karşıya iletir
+//
+//	ctx, cancel := utils.OutboundContext(c)
+//	defer cancel()
+//	err := utils.HttpGet(ctx, url, params, &model)
+func OutboundContext(c requestScope) (context.Context, context.CancelFunc) {
+	ctx := c.UserContext()
+	if rqID := c.Get(HeaderRequestID); rqID != "" {
+		ctx = WithRequestID(ctx, rqID)
+	}
+	if id := c.GetUserID(); id != 0 {
+		ctx = WithUserID(ctx, id)
+	}
+	if _, ok := ctx.Deadline(); ok {
+		return context.WithCancel(ctx)
+	}
+	// fiber context'inde deadline yok, server'ın write timeout'unu isteğin başından sayıyoruz
+	if cfg := config.Get(); cfg != nil && cfg.Server.WriteTimeout > 0 {
+		deadline := c.Context().Time().Add(time.Duration(cfg.Server.WriteTimeout) * time.Second)
+		return context.WithDeadline(ctx, deadline)
+	}
+	return context.WithCancel(ctx)
+}
+
+// Propagate context'teki request id, kullanıcı id ve kalan süreyi header olarak karşıya iletir
+func Propagate() Interceptor {
+	return OnRequest(func(req *http.Request) error {
+		ctx := req.Context()
+		if rqID := RequestIDFrom(ctx); rqID != "" && req.Header.Get(HeaderRequestID) == "" {
+			req.Header.Set(HeaderRequestID, rqID)
+		}
+		if id := UserIDFrom(ctx); id != 0 && req.Header.Get(HeaderUserID) == "" {
+			req.Header.Set(HeaderUserID, strconv.FormatInt(id, 10))
+		}
+		if deadline, ok := ctx.Deadline(); ok {
+			if remaining := time.Until(deadline); remaining > 0 {
+				req.Header.Set(HeaderRequestTimeout, strconv.FormatInt(remaining.Milliseconds(), 10))
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,162 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"golang.org/x/time/rate"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Limiter key başına istek hızını sınırlar. Wait izin çıkana veya ctx bitene kadar bekler
+type Limiter interface {
+	Wait(ctx context.Context, key string) error
+}
+
+// ErrRateLimitDeadline izin ctx'in deadline'ından önce çıkmayacaksa. tekrar denenmez
+var ErrRateLimitDeadline = errors.New("rate limit beklemesi context deadline'ını aşıyor")
+
+// LocalLimiter process içinde key başına token bucket
+type LocalLimiter struct {
+	limit rate.Limit
+	burst int
+
+	mu       sync.Mutex
+	limiters map[string]*rate.Limiter
+}
+
+// NewLocalLimiter saniyede perSecond istek, anlık en fazla burst istek
+func NewLocalLimiter(perSecond float64, burst int) *LocalLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &LocalLimiter{limit: rate.Limit(perSecond), burst: burst, limiters: map[string]*rate.Limiter{}}
+}
+
+func (l *LocalLimiter) Wait(ctx context.Context, key string) error {
+	l.mu.Lock()
+	lim, ok := l.limiters[key]
+	if !ok {
+		lim = rate.NewLimiter(l.limit, l.burst)
+		l.limiters[key] = lim
+	}
+	l.mu.Unlock()
+	if err := lim.Wait(ctx); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("%w: %v", ErrRateLimitDeadline, err)
+	}
+	return nil
+}
+
+// tokenBucketScript token'ları redis'in saatine göre doldurur. {izin, beklenecek ms} döner
+var tokenBucketScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil then
+	tokens = burst
+	ts = now
+end
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate / 1000)
+local allowed = 0
+local wait = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	wait = math.ceil((1 - tokens) * 1000 / rate)
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst * 1000 / rate) + 1000)
+return {allowed, wait}
+`)
+
+// RedisLimiter tüm replikaların ortak kullandığı token bucket.
+// redis'e ulaşılamazsa aynı ayarlarla process içi limiter'a düşer
+type RedisLimiter struct {
+	prefix    string
+	perSecond float64
+	burst     int
+	fallback  *LocalLimiter
+}
+
+func NewRedisLimiter(perSecond float64, burst int) *RedisLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RedisLimiter{
+		prefix:    "ratelimit:",
+		perSecond: perSecond,
+		burst:     burst,
+		fallback:  NewLocalLimiter(perSecond, burst),
+	}
+}
+
+func (l *RedisLimiter) Wait(ctx context.Context, key string) error {
+	for {
+		res, err := cache.RunScript(ctx, tokenBucketScript, []string{l.prefix + key}, l.perSecond, l.burst)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return l.fallback.Wait(ctx, key)
+		}
+		vals, ok := res.([]interface{})
+		if !ok || len(vals) != 2 {
+			return l.fallback.Wait(ctx, key)
+		}
+		allowed, _ := vals[0].(int64)
+		if allowed == 1 {
+			return nil
+		}
+		wait, _ := vals[1].(int64)
+		if wait <= 0 {
+			wait = 1
+		}
+		d := time.Duration(wait) * time.Millisecond
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < d {
+			return ErrRateLimitDeadline
+		}
+
+		timer := time.NewTimer(d)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// RateLimit her istekten önce limiter'dan izin bekler. key nil ise host bazında sınırlanır
+//
+//	// partner'a tüm replikalardan toplam saniyede 5 istek
+//	utils.RateLimit(utils.NewRedisLimiter(5, 5), utils.FixedKey("partner"))
+func RateLimit(l Limiter, key func(req *http.Request) string) Interceptor {
+	if key == nil {
+		key = func(req *http.Request) string { return req.URL.Host }
+	}
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if err := l.Wait(req.Context(), key(req)); err != nil {
+				closeRequestBody(req)
+				return nil, err
+			}
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+// FixedKey tüm istekleri aynı kovadan düşer. client bazında sınırlamak için
+func FixedKey(name string) func(req *http.Request) string {
+	return func(req *http.Request) string { return name }
+}
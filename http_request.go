@@ -0,0 +1,299 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const mimeJSON = "application/json; charset=utf-8"
+
+// Request outbound istekler için builder. hata olursa Do/Decode'da döner.
+//
+//	var model Model
+//	err := utils.NewRequest(http.MethodPut, "https://api.partner.com/kayit").
+//		Param("id", "5").
+//		Header("X-Api-Key", key).
+//		Timeout(5 * time.Second).
+//		JSON(body).
+//		Decode(ctx, &model)
+type Request struct {
+	method   string
+	url      string
+	rawQuery string
+	query    url.Values
+	header   http.Header
+	body     io.Reader
+	timeout  time.Duration
+	client   *http.Client
+	errModel interface{}
+	codec    Codec
+	err      error
+
+	maxResponseSize int64
+	streaming       bool
+
+	multipart      *multipartBody
+	contentLength  int64
+	uploadProgress ProgressFunc
+}
+
+func NewRequest(method, rawURL string) *Request {
+	r := &Request{
+		method: method,
+		url:    rawURL,
+		query:  url.Values{},
+		header: http.Header{},
+	}
+	r.header.Set("Accept", mimeJSON)
+	return r
+}
+
+// Param query'e bir parametre ekler
+func (r *Request) Param(key, value string) *Request {
+	r.query.Add(key, value)
+	return r
+}
+
+// Params verilen değerleri query'e ekler
+func (r *Request) Params(values url.Values) *Request {
+	for k, vs := range values {
+		for _, v := range vs {
+			r.query.Add(k, v)
+		}
+	}
+	return r
+}
+
+// RawQuery hazır encode edilmiş query string. parse edilmeden url'e eklenir, Param ile eklenenler ardına gelir
+func (r *Request) RawQuery(query string) *Request {
+	r.rawQuery = query
+	return r
+}
+
+func (r *Request) Header(key, value string) *Request {
+	r.header.Set(key, value)
+	return r
+}
+
+func (r *Request) Headers(header http.Header) *Request {
+	for k, vs := range header {
+		r.header[k] = append([]string(nil), vs...)
+	}
+	return r
+}
+
+// Timeout sadece bu istek için süre sınırı. response body okunana kadar geçerlidir
+func (r *Request) Timeout(d time.Duration) *Request {
+	r.timeout = d
+	return r
+}
+
+// Client default client yerine başka bir client kullanmak için
+func (r *Request) Client(c *http.Client) *Request {
+	r.client = c
+	return r
+}
+
+// ErrorModel 2xx dışında dönen json hata body'si v'ye decode edilir.
+// dönen hata yine *HTTPError olur
+func (r *Request) ErrorModel(v interface{}) *Request {
+	r.errModel = v
+	return r
+}
+
+// JSON body'i json'a çevirip gönderir. nil ise body gönderilmez
+func (r *Request) JSON(body interface{}) *Request {
+	if body == nil {
+		return r
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		r.err = errors.New("request body json'a çevrilemedi: " + err.Error())
+		return r
+	}
+	r.body = bytes.NewReader(data)
+	r.header.Set("Content-Type", mimeJSON)
+	return r
+}
+
+// Encode body'i verilen codec ile encode edip gönderir
+//
+//	utils.NewRequest(http.MethodPost, url).Encode(utils.XMLCodec, sorgu).Decode(ctx, &cevap)
+func (r *Request) Encode(codec Codec, body interface{}) *Request {
+	if body == nil {
+		return r
+	}
+	data, err := codec.Marshal(body)
+	if err != nil {
+		r.err = errors.New("request body encode edilemedi: " + err.Error())
+		return r
+	}
+	r.body = bytes.NewReader(data)
+	r.multipart = nil
+	r.header.Set("Content-Type", codec.ContentType())
+	return r
+}
+
+// XML body'i xml olarak gönderir
+func (r *Request) XML(body interface{}) *Request {
+	return r.Encode(XMLCodec, body)
+}
+
+// Codec cevabın Content-Type'ına bakmadan verilen codec ile decode edilir.
+// Content-Type'ı yanlış dönen servisler için
+func (r *Request) Codec(c Codec) *Request {
+	r.codec = c
+	return r
+}
+
+// Body ham body gönderir. reader *bytes.Reader, *bytes.Buffer veya *strings.Reader değilse
+// istek stream edilir ve tekrar denenemez
+func (r *Request) Body(body io.Reader, contentType string) *Request {
+	r.body = body
+	r.multipart = nil
+	if contentType != "" {
+		r.header.Set("Content-Type", contentType)
+	}
+	return r
+}
+
+func (r *Request) buildURL() (string, error) {
+	u, err := url.Parse(r.url)
+	if err != nil {
+		return "", err
+	}
+	// RawQuery olduğu gibi eklenir, HttpGet/HttpPost'un eski url+"?"+params davranışı sıra ve
+	// encoding dahil korunur. Param ile eklenenler sona gelir
+	parts := make([]string, 0, 3)
+	for _, part := range []string{u.RawQuery, r.rawQuery, r.query.Encode()} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	u.RawQuery = strings.Join(parts, "&")
+	return u.String(), nil
+}
+
+// Build http.Request'i oluşturur. cancel response ile işin bitince çağrılmalı
+func (r *Request) Build(ctx context.Context) (*http.Request, context.CancelFunc, error) {
+	if r.err != nil {
+		return nil, nil, r.err
+	}
+	target, err := r.buildURL()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if r.maxResponseSize != 0 {
+		ctx = WithMaxResponseSize(ctx, r.maxResponseSize)
+	} else if _, ok := ctx.Value(maxResponseSizeKey{}).(int64); r.streaming && !ok {
+		ctx = WithMaxResponseSize(ctx, -1)
+	}
+	cancel := context.CancelFunc(func() {})
+	if r.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, r.timeout)
+	}
+	body := r.body
+	var pipe *io.PipeReader
+	contentType := ""
+	if r.multipart != nil {
+		pipe, contentType = r.multipart.start()
+		body = pipe
+	}
+	req, err := http.NewRequestWithContext(ctx, r.method, target, body)
+	if err != nil {
+		if pipe != nil {
+			pipe.Close()
+		}
+		cancel()
+		return nil, nil, err
+	}
+	for k, vs := range r.header {
+		req.Header[k] = vs
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if r.contentLength > 0 {
+		req.ContentLength = r.contentLength
+	}
+	r.wrapUploadProgress(req)
+	return req, cancel, nil
+}
+
+// Do isteği atar ve response'u olduğu gibi döner, status kontrolü yapmaz.
+// body'i kapatmak caller'ın sorumluluğunda
+func (r *Request) Do(ctx context.Context) (*http.Response, error) {
+	req, cancel, err := r.Build(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c := r.client
+	if c == nil {
+		c = httpClient()
+	}
+	if r.streaming && c.Timeout > 0 {
+		// aynı transport, sadece toplam süre sınırı yok
+		noTimeout := *c
+		noTimeout.Timeout = 0
+		c = &noTimeout
+	}
+	res, err := c.Do(req)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	res.Body = &cancelOnClose{ReadCloser: res.Body, cancel: cancel}
+	return res, nil
+}
+
+// Decode isteği atar, 2xx dışındaki cevapları *HTTPError olarak döner ve body'i v'ye decode eder.
+// codec cevabın Content-Type'ına göre seçilir, bkz. CodecFor. v nil ise body okunmaz
+func (r *Request) Decode(ctx context.Context, v interface{}) error {
+	res, err := r.Do(ctx)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		httpErr := newHTTPError(res)
+		if r.errModel != nil {
+			httpErr.DecodeJSON(r.errModel)
+		}
+		return httpErr
+	}
+	if v == nil || res.StatusCode == http.StatusNoContent || r.method == http.MethodHead {
+		return nil
+	}
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+	codec := r.codec
+	if codec == nil {
+		codec = CodecFor(res.Header.Get("Content-Type"))
+	}
+	return codec.Unmarshal(data, v)
+}
+
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
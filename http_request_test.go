@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestBuildURLKeepsRawQuery(t *testing.T) {
+	cases := []struct {
+		url, raw string
+		params   map[string]string
+		want     string
+	}{
+		{"http://api.test/x", "b=2&a=1", nil, "http://api.test/x?b=2&a=1"},
+		{"http://api.test/x?z=1", "b=%zz;c", nil, "http://api.test/x?z=1&b=%zz;c"},
+		{"http://api.test/x", "b=2", map[string]string{"q": "v w"}, "http://api.test/x?b=2&q=v+w"},
+		{"http://api.test/x", "", nil, "http://api.test/x"},
+	}
+	for _, c := range cases {
+		r := NewRequest(http.MethodGet, c.url).RawQuery(c.raw)
+		for k, v := range c.params {
+			r.Param(k, v)
+		}
+		got, err := r.buildURL()
+		if err != nil || got != c.want {
+			t.Errorf("%s + %q: %q (%v), beklenen %q", c.url, c.raw, got, err, c.want)
+		}
+	}
+}
+
+func TestRequestDecode(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var in map[string]int
+		json.NewDecoder(r.Body).Decode(&in)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"method": r.Method,
+			"query":  r.URL.RawQuery,
+			"header": r.Header.Get("X-Api-Key"),
+			"x":      in["x"],
+		})
+	}))
+	defer srv.Close()
+
+	var out struct {
+		Method string `json:"method"`
+		Query  string `json:"query"`
+		Header string `json:"header"`
+		X      int    `json:"x"`
+	}
+	err := NewRequest(http.MethodPut, srv.URL).
+		Param("id", "5").
+		Header("X-Api-Key", "k").
+		Timeout(time.Second).
+		JSON(map[string]int{"x": 3}).
+		Decode(context.Background(), &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Method != http.MethodPut || out.Query != "id=5" || out.Header != "k" || out.X != 3 {
+		t.Fatalf("%+v", out)
+	}
+}
+
+func TestLegacyHttpGetQuery(t *testing.T) {
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	var out map[string]interface{}
+	if err := HttpGet(context.Background(), srv.URL, "sayfa=2&filtre=a,b", &out); err != nil {
+		t.Fatal(err)
+	}
+	if query != "sayfa=2&filtre=a,b" {
+		t.Fatalf("query değişti: %q", query)
+	}
+}
@@ -0,0 +1,173 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy geçici hatalarda (bağlantı hatası, 429, 5xx) isteğin nasıl tekrarlanacağını belirler
+type RetryPolicy struct {
+	// MaxAttempts ilk deneme dahil toplam deneme sayısı. 1 veya altı tekrar yok demek
+	MaxAttempts int
+	// BaseDelay ilk bekleme süresi, her denemede iki katına çıkar
+	BaseDelay time.Duration
+	// MaxDelay bekleme süresinin üst sınırı
+	MaxDelay time.Duration
+	// AllMethods true ise POST, PATCH gibi idempotent olmayan istekler de tekrarlanır
+	AllMethods bool
+}
+
+// DefaultRetryPolicy HttpGet/HttpPost'un kullandığı policy
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   200 * time.Millisecond,
+	MaxDelay:    5 * time.Second,
+}
+
+// NoRetry tekrar denemeyi kapatmak için
+var NoRetry = RetryPolicy{MaxAttempts: 1}
+
+type retryPolicyKey struct{}
+
+// WithRetryPolicy bu context ile atılan istekler için default policy'yi ezer
+//
+//	ctx = utils.WithRetryPolicy(ctx, utils.NoRetry)
+//	err := utils.HttpGet(ctx, url, params, &model)
+func WithRetryPolicy(ctx context.Context, policy RetryPolicy) context.Context {
+	return context.WithValue(ctx, retryPolicyKey{}, policy)
+}
+
+func retryPolicyFrom(ctx context.Context, def RetryPolicy) RetryPolicy {
+	if p, ok := ctx.Value(retryPolicyKey{}).(RetryPolicy); ok {
+		return p
+	}
+	return def
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func (p RetryPolicy) allows(req *http.Request) bool {
+	if p.MaxAttempts <= 1 {
+		return false
+	}
+	// body tekrar okunamıyorsa tekrar gönderemeyiz
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	return p.AllMethods || isIdempotent(req.Method)
+}
+
+// backoff attempt. denemeden sonra beklenecek süre, yarısı sabit yarısı rastgele
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.BaseDelay << uint(attempt-1)
+	if d <= 0 || (p.MaxDelay > 0 && d > p.MaxDelay) {
+		d = p.MaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+func shouldRetry(ctx context.Context, res *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		return !errors.Is(err, ErrCircuitOpen) && !errors.Is(err, ErrRateLimitDeadline)
+	}
+	if res.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	return res.StatusCode >= 500 && res.StatusCode != http.StatusNotImplemented
+}
+
+// retryAfter Retry-After header'ını saniye veya http tarihi olarak okur
+func retryAfter(res *http.Response) time.Duration {
+	if res == nil {
+		return 0
+	}
+	v := res.Header.Get("Retry-After")
+	if v == "" {
+		return 0
+	}
+	if sec, err := strconv.Atoi(v); err == nil && sec > 0 {
+		return time.Duration(sec) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
+
+type retryTransport struct {
+	next http.RoundTripper
+	// policy nil ise DefaultRetryPolicy kullanılır
+	policy *RetryPolicy
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	def := DefaultRetryPolicy
+	if t.policy != nil {
+		def = *t.policy
+	}
+	policy := retryPolicyFrom(ctx, def)
+	if !policy.allows(req) {
+		return t.next.RoundTrip(req)
+	}
+
+	for attempt := 1; ; attempt++ {
+		r := req
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r = req.Clone(ctx)
+			r.Body = body
+		}
+
+		res, err := t.next.RoundTrip(r)
+		if attempt >= policy.MaxAttempts || !shouldRetry(ctx, res, err) {
+			return res, err
+		}
+
+		wait := policy.backoff(attempt)
+		if ra := retryAfter(res); ra > 0 {
+			wait = ra
+			// sunucu bizi saatlerce bekletemesin
+			if policy.MaxDelay > 0 && wait > policy.MaxDelay {
+				wait = policy.MaxDelay
+			}
+		}
+		// deadline'a yetişemeyeceksek son cevabı olduğu gibi dönüyoruz
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			return res, err
+		}
+		if res != nil {
+			io.Copy(io.Discard, io.LimitReader(res.Body, 4096))
+			res.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
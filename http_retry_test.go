@@ -0,0 +1,115 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func statusTransport(statuses ...int) (http.RoundTripper, *int) {
+	calls := new(int)
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		status := statuses[len(statuses)-1]
+		if *calls < len(statuses) {
+			status = statuses[*calls]
+		}
+		*calls++
+		return &http.Response{StatusCode: status, Header: http.Header{}, Body: io.NopCloser(strings.NewReader("")), Request: req}, nil
+	}), calls
+}
+
+func TestRetryTransportRetriesIdempotent(t *testing.T) {
+	next, calls := statusTransport(503, 502, 200)
+	tr := &retryTransport{next: next, policy: &RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://api.test/x", nil)
+	res, err := tr.RoundTrip(req)
+	if err != nil || res.StatusCode != 200 || *calls != 3 {
+		t.Fatalf("res=%v err=%v calls=%d", res, err, *calls)
+	}
+}
+
+func TestRetryTransportSkipsPost(t *testing.T) {
+	next, calls := statusTransport(503, 200)
+	tr := &retryTransport{next: next, policy: &RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}}
+
+	req, _ := http.NewRequest(http.MethodPost, "http://api.test/x", strings.NewReader("{}"))
+	res, _ := tr.RoundTrip(req)
+	if res.StatusCode != 503 || *calls != 1 {
+		t.Fatalf("status=%d calls=%d", res.StatusCode, *calls)
+	}
+}
+
+func TestRetryTransportClampsRetryAfter(t *testing.T) {
+	calls := 0
+	next := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		res := &http.Response{StatusCode: 200, Header: http.Header{}, Body: http.NoBody}
+		if calls == 1 {
+			res.StatusCode = http.StatusTooManyRequests
+			res.Header.Set("Retry-After", "3600")
+		}
+		return res, nil
+	})
+	tr := &retryTransport{next: next, policy: &RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: 20 * time.Millisecond}}
+
+	start := time.Now()
+	req, _ := http.NewRequest(http.MethodGet, "http://api.test/x", nil)
+	res, err := tr.RoundTrip(req)
+	if err != nil || res.StatusCode != 200 {
+		t.Fatalf("res=%v err=%v", res, err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Retry-After MaxDelay ile sınırlanmadı: %v", elapsed)
+	}
+}
+
+func TestShouldRetry(t *testing.T) {
+	ctx := context.Background()
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	cases := []struct {
+		name   string
+		ctx    context.Context
+		status int
+		err    error
+		want   bool
+	}{
+		{"5xx", ctx, 503, nil, true},
+		{"429", ctx, 429, nil, true},
+		{"501", ctx, 501, nil, false},
+		{"4xx", ctx, 404, nil, false},
+		{"bağlantı hatası", ctx, 0, io.ErrUnexpectedEOF, true},
+		{"breaker açık", ctx, 0, &CircuitOpenError{Host: "h"}, false},
+		{"limiter deadline", ctx, 0, fmt.Errorf("%w: x", ErrRateLimitDeadline), false},
+		{"iptal", cancelled, 503, nil, false},
+	}
+	for _, c := range cases {
+		var res *http.Response
+		if c.err == nil {
+			res = &http.Response{StatusCode: c.status}
+		}
+		if got := shouldRetry(c.ctx, res, c.err); got != c.want {
+			t.Errorf("%s: shouldRetry=%v, beklenen %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestBackoffBounds(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	for attempt := 1; attempt <= 10; attempt++ {
+		d := p.backoff(attempt)
+		full := p.BaseDelay << uint(attempt-1)
+		if full > p.MaxDelay || full <= 0 {
+			full = p.MaxDelay
+		}
+		if d < full/2 || d > full {
+			t.Fatalf("attempt %d: %v, [%v, %v] aralığında olmalı", attempt, d, full/2, full)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package utils
+
+import (
+	"context"
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const tracerName = "web"
+
+func tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// fasthttpCarrier gelen isteğin header'larını propagator'a açar
+type fasthttpCarrier struct {
+	h *fasthttp.RequestHeader
+}
+
+func (c fasthttpCarrier) Get(key string) string {
+	return string(c.h.Peek(key))
+}
+
+func (c fasthttpCarrier) Set(key, value string) {
+	c.h.Set(key, value)
+}
+
+func (c fasthttpCarrier) Keys() []string {
+	var keys []string
+	c.h.VisitAll(func(k, v []byte) {
+		keys = append(keys, string(k))
+	})
+	return keys
+}
+
+// TracingMiddleware her istek için bir server span açar. gelen traceparent header'ı varsa
+// span onun altına eklenir. span UserContext'e konur, AppCtx üzerinden yapılan db, cache
+// ve dış istekler bu span'ın altında görünür
+//
+//	app.Use(utils.TracingMiddleware())
+func TracingMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := otel.GetTextMapPropagator().Extract(c.UserContext(), fasthttpCarrier{h: &c.Request().Header})
+		// fiber'ın string'leri istek bitince tekrar kullanılır, span'a kopyası verilmeli
+		method := strings.Clone(c.Method())
+		ctx, span := tracer().Start(ctx, method+" "+c.Path(),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.method", method),
+				attribute.String("http.target", strings.Clone(c.OriginalURL())),
+			),
+		)
+		defer span.End()
+		c.SetUserContext(ctx)
+
+		// hata varsa status'u görebilmek için error handler burada çalıştırılır, fiber'ın logger middleware'i gibi
+		err := c.Next()
+		if err != nil {
+			if err := c.App().ErrorHandler(c, err); err != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+		// içerideki metrics middleware'i hatayı işleyip nil dönmüş olabilir
+		if handled, ok := c.Locals(handledErrorLocal).(error); ok {
+			err = handled
+		}
+		if err != nil {
+			span.RecordError(err)
+		}
+
+		status := c.Response().StatusCode()
+		route := c.Route().Path
+		span.SetName(method + " " + route)
+		span.SetAttributes(
+			attribute.String("http.route", route),
+			attribute.Int("http.status_code", status),
+		)
+		if rid := c.Get(HeaderRequestID); rid != "" {
+			span.SetAttributes(attribute.String("requestid", strings.Clone(rid)))
+		}
+		if status >= 500 {
+			span.SetStatus(codes.Error, strconv.Itoa(status))
+		}
+		return nil
+	}
+}
+
+// Trace dış istekler için client span açar ve traceparent header'ını ekler
+func Trace() Interceptor {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			ctx, span := tracer().Start(req.Context(), "HTTP "+req.Method+" "+req.URL.Host,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(
+					attribute.String("http.method", req.Method),
+					attribute.String("http.url", req.URL.Redacted()),
+					attribute.String("net.peer.name", req.URL.Host),
+				),
+			)
+			defer span.End()
+
+			out := req.Clone(ctx)
+			otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(out.Header))
+
+			res, err := next.RoundTrip(out)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				return nil, err
+			}
+			span.SetAttributes(attribute.Int("http.status_code", res.StatusCode))
+			if res.StatusCode >= 500 {
+				span.SetStatus(codes.Error, res.Status)
+			}
+			return res, nil
+		})
+	}
+}
+
+// GormTracing her sorgu için statement context'indeki span'ın altına bir span açar.
+// db'nin WithContext ile kullanılması gerekir, AppCtx.Db bunu yapar
+//
+//	database.DB().Use(utils.GormTracing{})
+type GormTracing struct{}
+
+func (GormTracing) Name() string {
+	return "tracing"
+}
+
+const gormSpanKey = "tracing:span"
+
+func (p GormTracing) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	hooks := []struct {
+		name   string
+		before func(name string, fn func(*gorm.DB)) error
+		after  func(name string, fn func(*gorm.DB)) error
+	}{
+		{"create", cb.Create().Before("gorm:create").Register, cb.Create().After("gorm:create").Register},
+		{"query", cb.Query().Before("gorm:query").Register, cb.Query().After("gorm:query").Register},
+		{"update", cb.Update().Before("gorm:update").Register, cb.Update().After("gorm:update").Register},
+		{"delete", cb.Delete().Before("gorm:delete").Register, cb.Delete().After("gorm:delete").Register},
+		{"row", cb.Row().Before("gorm:row").Register, cb.Row().After("gorm:row").Register},
+		{"raw", cb.Raw().Before("gorm:raw").Register, cb.Raw().After("gorm:raw").Register},
+	}
+	for _, h := range hooks {
+		if err := h.before("tracing:before_"+h.name, p.before("gorm."+h.name)); err != nil {
+			return err
+		}
+		if err := h.after("tracing:after_"+h.name, p.after); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (GormTracing) before(name string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx := db.Statement.Context
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		_, span := tracer().Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(attribute.String("db.system", db.Dialector.Name())))
+		db.InstanceSet(gormSpanKey, span)
+	}
+}
+
+func (GormTracing) after(db *gorm.DB) {
+	v, ok := db.InstanceGet(gormSpanKey)
+	if !ok {
+		return
+	}
+	span, ok := v.(trace.Span)
+	if !ok {
+		return
+	}
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("db.statement", db.Statement.SQL.String()),
+		attribute.String("db.table", db.Statement.Table),
+		attribute.Int64("db.rows_affected", db.Statement.RowsAffected),
+	)
+	if err := db.Error; err != nil && err != gorm.ErrRecordNotFound {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
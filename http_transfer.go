@@ -0,0 +1,236 @@
+package utils
+
+import (
+	"context"
+	"encoding/hex"
+	"hash"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ProgressFunc transfer ilerledikçe çağrılır. total bilinmiyorsa -1'dir
+type ProgressFunc func(transferred, total int64)
+
+// FormFile multipart isteklerde gönderilecek dosya. Reader sonuna kadar stream edilir
+type FormFile struct {
+	Field       string
+	FileName    string
+	ContentType string
+	Reader      io.Reader
+}
+
+// Form url.Values'u application/x-www-form-urlencoded olarak gönderir
+func (r *Request) Form(values url.Values) *Request {
+	return r.Body(strings.NewReader(values.Encode()), "application/x-www-form-urlencoded")
+}
+
+// Multipart alanları ve dosyaları multipart/form-data olarak stream eder.
+// body hafızaya alınmadığı için istek tekrar denenmez
+//
+//	f, _ := os.Open("fatura.pdf")
+//	defer f.Close()
+//	err := utils.NewRequest(http.MethodPost, url).
+//		Multipart(url.Values{"aciklama": {"ocak"}}, utils.FormFile{Field: "dosya", FileName: "fatura.pdf", Reader: f}).
+//		Decode(ctx, &sonuc)
+func (r *Request) Multipart(fields url.Values, files ...FormFile) *Request {
+	r.multipart = &multipartBody{fields: fields, files: files}
+	r.body = nil
+	return r
+}
+
+type multipartBody struct {
+	fields url.Values
+	files  []FormFile
+}
+
+// start pipe'ı açıp yazmaya başlar. body okunmazsa reader kapatılmalı, yoksa goroutine bekler
+func (m *multipartBody) start() (*io.PipeReader, string) {
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+	go func() {
+		pw.CloseWithError(writeMultipart(mw, m.fields, m.files))
+	}()
+	return pr, mw.FormDataContentType()
+}
+
+func writeMultipart(mw *multipart.Writer, fields url.Values, files []FormFile) error {
+	for k, vs := range fields {
+		for _, v := range vs {
+			if err := mw.WriteField(k, v); err != nil {
+				return err
+			}
+		}
+	}
+	for _, f := range files {
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", multipart.FileContentDisposition(f.Field, f.FileName))
+		ct := f.ContentType
+		if ct == "" {
+			ct = "application/octet-stream"
+		}
+		h.Set("Content-Type", ct)
+		part, err := mw.CreatePart(h)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(part, f.Reader); err != nil {
+			return err
+		}
+	}
+	return mw.Close()
+}
+
+// Streaming büyük upload ve indirmeler için. client'ın Timeout'u ve response boyut sınırı
+// uygulanmaz, süre ctx ile belirlenir. sınır istenirse MaxResponseSize ayrıca verilebilir
+//
+//	ctx, cancel := context.WithTimeout(ctx, 30*time.Minute)
+//	defer cancel()
+//	err := utils.NewRequest(http.MethodPost, url).Streaming().Multipart(nil, dosya).Decode(ctx, &sonuc)
+func (r *Request) Streaming() *Request {
+	r.streaming = true
+	return r
+}
+
+// ContentLength stream edilen body'nin boyutu biliniyorsa verilebilir
+func (r *Request) ContentLength(n int64) *Request {
+	r.contentLength = n
+	return r
+}
+
+// UploadProgress body gönderildikçe fn'i çağırır
+func (r *Request) UploadProgress(fn ProgressFunc) *Request {
+	r.uploadProgress = fn
+	return r
+}
+
+type progressReader struct {
+	io.ReadCloser
+	total int64
+	done  int64
+	fn    ProgressFunc
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.ReadCloser.Read(b)
+	if n > 0 {
+		p.done += int64(n)
+		p.fn(p.done, p.total)
+	}
+	return n, err
+}
+
+type progressWriter struct {
+	w     io.Writer
+	total int64
+	done  int64
+	fn    ProgressFunc
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	if n > 0 {
+		p.done += int64(n)
+		if p.fn != nil {
+			p.fn(p.done, p.total)
+		}
+	}
+	return n, err
+}
+
+// ChecksumError indirilen dosyanın hash'i beklenenle uyuşmadığında
+type ChecksumError struct {
+	Expected string
+	Actual   string
+}
+
+func (e *ChecksumError) Error() string {
+	return "checksum uyuşmuyor: beklenen " + e.Expected + ", gelen " + e.Actual
+}
+
+// DownloadOptions indirme sırasında ilerleme ve bütünlük kontrolü
+type DownloadOptions struct {
+	Progress ProgressFunc
+	// Hash verilirse indirilen veri bununla hash'lenir, örn. sha256.New()
+	Hash hash.Hash
+	// Checksum Hash'in beklenen hex değeri. boşsa kontrol yapılmaz
+	Checksum string
+}
+
+// Download cevabı hafızaya almadan w'ye stream eder, yazılan byte sayısını döner.
+// istek Streaming olarak atılır, süreyi ctx belirler
+func (r *Request) Download(ctx context.Context, w io.Writer, opts DownloadOptions) (int64, error) {
+	res, err := r.Streaming().Do(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return 0, newHTTPError(res)
+	}
+
+	dst := w
+	if opts.Hash != nil {
+		opts.Hash.Reset()
+		dst = io.MultiWriter(w, opts.Hash)
+	}
+	pw := &progressWriter{w: dst, total: res.ContentLength, fn: opts.Progress}
+	n, err := io.Copy(pw, res.Body)
+	if err != nil {
+		return n, err
+	}
+
+	if opts.Hash != nil && opts.Checksum != "" {
+		actual := hex.EncodeToString(opts.Hash.Sum(nil))
+		if !strings.EqualFold(actual, opts.Checksum) {
+			return n, &ChecksumError{Expected: opts.Checksum, Actual: actual}
+		}
+	}
+	return n, nil
+}
+
+// DownloadFile cevabı dosyaya yazar. önce geçici bir dosyaya yazılır, indirme ve checksum
+// başarılıysa asıl isme taşınır. yarım kalan dosya silinir
+func (r *Request) DownloadFile(ctx context.Context, path string, opts DownloadOptions) (int64, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.part")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+
+	n, err := r.Download(ctx, tmp, opts)
+	if err != nil {
+		tmp.Close()
+		return n, err
+	}
+	if err := tmp.Close(); err != nil {
+		return n, err
+	}
+	return n, os.Rename(tmp.Name(), path)
+}
+
+func (r *Request) wrapUploadProgress(req *http.Request) {
+	if r.uploadProgress == nil || req.Body == nil || req.Body == http.NoBody {
+		return
+	}
+	total := req.ContentLength
+	if total == 0 {
+		total = -1
+	}
+	req.Body = &progressReader{ReadCloser: req.Body, total: total, fn: r.uploadProgress}
+	if getBody := req.GetBody; getBody != nil {
+		req.GetBody = func() (io.ReadCloser, error) {
+			body, err := getBody()
+			if err != nil {
+				return nil, err
+			}
+			return &progressReader{ReadCloser: body, total: total, fn: r.uploadProgress}, nil
+		}
+	}
+}
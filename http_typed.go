@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+)
+
+// Send builder ile hazırlanan isteği atıp cevabı T olarak döner
+//
+//	kur, err := utils.Send[Kur](ctx, utils.NewRequest(http.MethodGet, url).Param("doviz", "USD"))
+func Send[T any](ctx context.Context, r *Request) (T, error) {
+	var result T
+	err := r.Decode(ctx, &result)
+	return result, err
+}
+
+// Get GET isteği atıp cevabı T olarak döner
+func Get[T any](ctx context.Context, url string, params url.Values) (T, error) {
+	return Send[T](ctx, NewRequest(http.MethodGet, url).Params(params))
+}
+
+// Post body'i json olarak gönderip cevabı Resp olarak döner
+func Post[Req, Resp any](ctx context.Context, url string, body Req) (Resp, error) {
+	return Send[Resp](ctx, NewRequest(http.MethodPost, url).JSON(body))
+}
+
+func Put[Req, Resp any](ctx context.Context, url string, body Req) (Resp, error) {
+	return Send[Resp](ctx, NewRequest(http.MethodPut, url).JSON(body))
+}
+
+func Patch[Req, Resp any](ctx context.Context, url string, body Req) (Resp, error) {
+	return Send[Resp](ctx, NewRequest(http.MethodPatch, url).JSON(body))
+}
+
+func Delete[T any](ctx context.Context, url string, params url.Values) (T, error) {
+	return Send[T](ctx, NewRequest(http.MethodDelete, url).Params(params))
+}
+
+// Envelope context.ResponseModel'in tipli hali. bizim servislerimizin cevaplarını decode etmek için
+type Envelope[T any] struct {
+	Data      T      `json:"data"`
+	Message   string `json:"message"`
+	HataVarMi bool   `json:"hataVarMi"`
+}
+
+// EnvelopeError karşı servis hataVarMi: true döndüğünde
+type EnvelopeError struct {
+	Message string
+	// Err status 2xx değilse *HTTPError, değilse nil
+	Err error
+}
+
+func (e *EnvelopeError) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *EnvelopeError) Unwrap() error {
+	return e.Err
+}
+
+// SendEnvelope ResponseModel dönen bir servise istek atıp Data alanını T olarak döner.
+// hataVarMi true ise veya status 2xx değilse mesajı ile birlikte *EnvelopeError döner
+//
+//	kullanici, err := utils.SendEnvelope[model.Kullanici](ctx, utils.NewRequest(http.MethodGet, url))
+func SendEnvelope[T any](ctx context.Context, r *Request) (T, error) {
+	var env Envelope[T]
+	var errEnv Envelope[interface{}]
+	err := r.ErrorModel(&errEnv).Decode(ctx, &env)
+	if err != nil {
+		var httpErr *HTTPError
+		if errors.As(err, &httpErr) && errEnv.Message != "" {
+			return env.Data, &EnvelopeError{Message: errEnv.Message, Err: err}
+		}
+		return env.Data, err
+	}
+	if env.HataVarMi {
+		return env.Data, &EnvelopeError{Message: env.Message}
+	}
+	return env.Data, nil
+}
+
+// GetEnvelope ResponseModel dönen bir servise GET isteği atar
+func GetEnvelope[T any](ctx context.Context, url string, params url.Values) (T, error) {
+	return SendEnvelope[T](ctx, NewRequest(http.MethodGet, url).Params(params))
+}
+
+// PostEnvelope ResponseModel dönen bir servise POST isteği atar
+func PostEnvelope[Req, Resp any](ctx context.Context, url string, body Req) (Resp, error) {
+	return SendEnvelope[Resp](ctx, NewRequest(http.MethodPost, url).JSON(body))
+}
@@ -0,0 +1,287 @@
+package httpmock
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Matcher bir isteğin stub'a uyup uymadığına karar verir
+type Matcher func(req *http.Request, body []byte) bool
+
+func Method(method string) Matcher {
+	return func(req *http.Request, body []byte) bool { return req.Method == method }
+}
+
+// Path url path'i birebir karşılaştırır
+func Path(path string) Matcher {
+	return func(req *http.Request, body []byte) bool { return req.URL.Path == path }
+}
+
+func Host(host string) Matcher {
+	return func(req *http.Request, body []byte) bool { return req.URL.Host == host }
+}
+
+// Query query parametresinin değerini kontrol eder
+func Query(key, value string) Matcher {
+	return func(req *http.Request, body []byte) bool { return req.URL.Query().Get(key) == value }
+}
+
+func Header(key, value string) Matcher {
+	return func(req *http.Request, body []byte) bool { return req.Header.Get(key) == value }
+}
+
+func BodyContains(substr string) Matcher {
+	return func(req *http.Request, body []byte) bool { return bytes.Contains(body, []byte(substr)) }
+}
+
+// BodyJSON body'i json olarak v ile karşılaştırır, alan sırası ve boşluklar önemsizdir
+func BodyJSON(v interface{}) Matcher {
+	expected, _ := json.Marshal(v)
+	var want interface{}
+	json.Unmarshal(expected, &want)
+	return func(req *http.Request, body []byte) bool {
+		var got interface{}
+		if err := json.Unmarshal(body, &got); err != nil {
+			return false
+		}
+		return reflect.DeepEqual(want, got)
+	}
+}
+
+// Call mock'a gelen bir istek. Body okunmuş halidir
+type Call struct {
+	Request *http.Request
+	Body    []byte
+}
+
+// Mock programlanabilir bir http.RoundTripper. eşleşmeyen istekler testi fail eder
+//
+//	mock := httpmock.New(t)
+//	mock.On(httpmock.Method(http.MethodGet), httpmock.Path("/kurlar")).RespondJSON(200, kurlar).Times(1)
+//	mock.Install()
+//	...
+//	mock.AssertExpectations()
+type Mock struct {
+	t testing.TB
+
+	mu    sync.Mutex
+	stubs []*Stub
+	calls []Call
+}
+
+func New(t testing.TB) *Mock {
+	return &Mock{t: t}
+}
+
+// On verilen matcher'ların hepsine uyan istekler için bir stub ekler.
+// birden fazla stub uyarsa ilk eklenen kullanılır
+func (m *Mock) On(matchers ...Matcher) *Stub {
+	s := &Stub{t: m.t, matchers: matchers, status: http.StatusOK, header: http.Header{}}
+	m.mu.Lock()
+	m.stubs = append(m.stubs, s)
+	m.mu.Unlock()
+	return s
+}
+
+func (m *Mock) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		body, _ = io.ReadAll(req.Body)
+		req.Body.Close()
+	}
+
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Request: req, Body: body})
+	var stub *Stub
+	for _, s := range m.stubs {
+		if s.available() && s.match(req, body) {
+			stub = s
+			s.calls++
+			break
+		}
+	}
+	m.mu.Unlock()
+
+	if stub == nil {
+		m.t.Errorf("httpmock: eşleşen stub yok: %s %s\n%s", req.Method, req.URL, body)
+		return nil, errors.New("httpmock: eşleşen stub yok: " + req.Method + " " + req.URL.String())
+	}
+	return stub.respond(req, body)
+}
+
+// Client mock'u kullanan bir http client
+func (m *Mock) Client() *http.Client {
+	return &http.Client{Transport: m}
+}
+
+// Install mock'u default client olarak kaydeder, HttpGet/HttpPost mock'a gider.
+// test bitince eski client geri konur
+func (m *Mock) Install() {
+	Install(m.t, m)
+}
+
+// Install verilen transport'u default client olarak kaydeder, test bitince eskisini geri koyar
+func Install(t testing.TB, rt http.RoundTripper) {
+	prev := utils.DefaultClient()
+	utils.RegisterClient(&utils.Client{Name: utils.DefaultClientName, HTTP: &http.Client{Transport: rt}})
+	t.Cleanup(func() { utils.RegisterClient(prev) })
+}
+
+func (m *Mock) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+// CallCount matcher'lara uyan istek sayısı
+func (m *Mock) CallCount(matchers ...Matcher) int {
+	n := 0
+	for _, c := range m.Calls() {
+		if matchAll(matchers, c.Request, c.Body) {
+			n++
+		}
+	}
+	return n
+}
+
+func (m *Mock) AssertCalled(matchers ...Matcher) {
+	m.t.Helper()
+	if m.CallCount(matchers...) == 0 {
+		m.t.Errorf("httpmock: beklenen istek gelmedi, gelen istekler:\n%s", m.describeCalls())
+	}
+}
+
+func (m *Mock) AssertNotCalled(matchers ...Matcher) {
+	m.t.Helper()
+	if n := m.CallCount(matchers...); n > 0 {
+		m.t.Errorf("httpmock: gelmemesi gereken istek %d kere geldi", n)
+	}
+}
+
+// AssertExpectations Times verilen stub'lar tam o kadar, diğerleri en az bir kere çağrılmış olmalı
+func (m *Mock) AssertExpectations() {
+	m.t.Helper()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, s := range m.stubs {
+		switch {
+		case s.times > 0 && s.calls != s.times:
+			m.t.Errorf("httpmock: %d. stub %d kere beklendi, %d kere çağrıldı", i+1, s.times, s.calls)
+		case s.times == 0 && s.calls == 0:
+			m.t.Errorf("httpmock: %d. stub hiç çağrılmadı", i+1)
+		}
+	}
+}
+
+func (m *Mock) describeCalls() string {
+	var sb strings.Builder
+	for _, c := range m.Calls() {
+		fmt.Fprintf(&sb, "  %s %s\n", c.Request.Method, c.Request.URL)
+	}
+	return sb.String()
+}
+
+func matchAll(matchers []Matcher, req *http.Request, body []byte) bool {
+	for _, match := range matchers {
+		if !match(req, body) {
+			return false
+		}
+	}
+	return true
+}
+
+// Stub bir isteğe dönülecek cevap
+type Stub struct {
+	t        testing.TB
+	matchers []Matcher
+	status   int
+	header   http.Header
+	body     []byte
+	err      error
+	fn       func(req *http.Request) (*http.Response, error)
+	times    int
+	calls    int
+}
+
+func (s *Stub) match(req *http.Request, body []byte) bool {
+	return matchAll(s.matchers, req, body)
+}
+
+func (s *Stub) available() bool {
+	return s.times == 0 || s.calls < s.times
+}
+
+func (s *Stub) Respond(status int, body string) *Stub {
+	s.status = status
+	s.body = []byte(body)
+	return s
+}
+
+func (s *Stub) RespondJSON(status int, v interface{}) *Stub {
+	s.t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		s.t.Fatalf("httpmock: RespondJSON: %v", err)
+	}
+	s.header.Set("Content-Type", "application/json; charset=utf-8")
+	s.status = status
+	s.body = data
+	return s
+}
+
+// RespondError istek bağlantı hatası almış gibi err döner
+func (s *Stub) RespondError(err error) *Stub {
+	s.err = err
+	return s
+}
+
+// RespondWith cevabı fn üretir
+func (s *Stub) RespondWith(fn func(req *http.Request) (*http.Response, error)) *Stub {
+	s.fn = fn
+	return s
+}
+
+func (s *Stub) Header(key, value string) *Stub {
+	s.header.Set(key, value)
+	return s
+}
+
+// Times stub tam n kere kullanılır, sonra sıradaki eşleşen stub'a geçilir
+func (s *Stub) Times(n int) *Stub {
+	s.times = n
+	return s
+}
+
+// Once Times(1) ile aynı
+func (s *Stub) Once() *Stub {
+	return s.Times(1)
+}
+
+func (s *Stub) respond(req *http.Request, body []byte) (*http.Response, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	if s.fn != nil {
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		return s.fn(req)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", s.status, http.StatusText(s.status)),
+		StatusCode:    s.status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        s.header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(s.body)),
+		ContentLength: int64(len(s.body)),
+		Request:       req,
+	}, nil
+}
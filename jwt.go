@@ -16,6 +16,12 @@ func jwtErrorHandler(c *fiber.Ctx, err error) error {
 	}
 }
 
+// Protected login gerektiren route'lar için
+// app.Get("/profil", Protected(), CtxWrap(handlers.Profil))
+func Protected() fiber.Handler {
+	return jwtSuccessHandler
+}
+
 func jwtSuccessHandler(c *fiber.Ctx) error {
 	kullanici := model.Kullanici{}
 	tokenByte := c.Request().Header.Peek("Authorization")
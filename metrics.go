@@ -0,0 +1,154 @@
+package metrics
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"gorm.io/gorm"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const namespace = "web"
+
+// Registry uygulamanın tüm metrikleri. go runtime ve process metrikleri de dahil
+var Registry = prometheus.NewRegistry()
+
+var (
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "gelen istek sayısı",
+	}, []string{"method", "route", "status"})
+
+	HTTPDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "gelen isteklerin süresi",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	Errors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "errors_total",
+		Help:      "ErrorHandler'a düşen hatalar, ErrorType bazında",
+	}, []string{"type"})
+
+	CacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_requests_total",
+		Help:      "cache okumaları. result: hit, miss, error",
+	}, []string{"result"})
+
+	OutboundDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "outbound_request_duration_seconds",
+		Help:      "dış servislere atılan isteklerin süresi",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"host", "method", "status"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests,
+		HTTPDuration,
+		Errors,
+		CacheRequests,
+		OutboundDuration,
+		redisCollector{},
+	)
+}
+
+// Handler /metrics endpoint'i
+//
+//	app.Get("/metrics", metrics.Handler())
+func Handler() fiber.Handler {
+	return adaptor.HTTPHandler(promhttp.HandlerFor(Registry, promhttp.HandlerOpts{}))
+}
+
+// Middleware istek sayısını ve süresini route ve status bazında ölçer.
+// label'lar route pattern'i ile tutulur, /kullanici/5 değil /kullanici/:id
+//
+//	app.Use(metrics.Middleware())
+func Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		method := strings.Clone(c.Method())
+
+		// status'u görebilmek için error handler burada çalıştırılır
+		if err := c.Next(); err != nil {
+			if err := c.App().ErrorHandler(c, err); err != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+
+		status := strconv.Itoa(c.Response().StatusCode())
+		route := c.Route().Path
+		HTTPRequests.WithLabelValues(method, route, status).Inc()
+		HTTPDuration.WithLabelValues(method, route, status).Observe(time.Since(start).Seconds())
+		return nil
+	}
+}
+
+// CacheHit, CacheMiss ve CacheError GetFromCache tarafından çağrılır
+func CacheHit() {
+	CacheRequests.WithLabelValues("hit").Inc()
+}
+
+func CacheMiss() {
+	CacheRequests.WithLabelValues("miss").Inc()
+}
+
+func CacheError() {
+	CacheRequests.WithLabelValues("error").Inc()
+}
+
+// RegisterDB gorm bağlantı havuzunun istatistiklerini yayınlar. db açıldıktan sonra bir kere çağrılmalı
+//
+//	metrics.RegisterDB("main", database.DB())
+func RegisterDB(name string, db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return Registry.Register(collectors.NewDBStatsCollector(sqlDB, name))
+}
+
+// redisCollector redis bağlantı havuzunun istatistiklerini scrape anında okur
+type redisCollector struct{}
+
+var (
+	redisHits     = prometheus.NewDesc(namespace+"_redis_pool_hits_total", "havuzda boş bağlantı bulunan sayısı", nil, nil)
+	redisMisses   = prometheus.NewDesc(namespace+"_redis_pool_misses_total", "havuzda boş bağlantı bulunamayan sayısı", nil, nil)
+	redisTimeouts = prometheus.NewDesc(namespace+"_redis_pool_timeouts_total", "havuzdan bağlantı beklerken timeout sayısı", nil, nil)
+	redisTotal    = prometheus.NewDesc(namespace+"_redis_pool_conns", "havuzdaki toplam bağlantı", nil, nil)
+	redisIdle     = prometheus.NewDesc(namespace+"_redis_pool_idle_conns", "havuzdaki boş bağlantı", nil, nil)
+	redisStale    = prometheus.NewDesc(namespace+"_redis_pool_stale_conns_total", "eskidiği için kapatılan bağlantı", nil, nil)
+)
+
+func (redisCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- redisHits
+	ch <- redisMisses
+	ch <- redisTimeouts
+	ch <- redisTotal
+	ch <- redisIdle
+	ch <- redisStale
+}
+
+func (redisCollector) Collect(ch chan<- prometheus.Metric) {
+	s := cache.PoolStats()
+	if s == nil {
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(redisHits, prometheus.CounterValue, float64(s.Hits))
+	ch <- prometheus.MustNewConstMetric(redisMisses, prometheus.CounterValue, float64(s.Misses))
+	ch <- prometheus.MustNewConstMetric(redisTimeouts, prometheus.CounterValue, float64(s.Timeouts))
+	ch <- prometheus.MustNewConstMetric(redisTotal, prometheus.GaugeValue, float64(s.TotalConns))
+	ch <- prometheus.MustNewConstMetric(redisIdle, prometheus.GaugeValue, float64(s.IdleConns))
+	ch <- prometheus.MustNewConstMetric(redisStale, prometheus.CounterValue, float64(s.StaleConns))
+}
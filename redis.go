@@ -1,41 +1,152 @@
 
 import (
 	"context"
+	"fmt"
 	"github.com/go-redis/redis/v8"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"time"
 )
 
-var rdb *redis.Client
-
+// Setup redis backend'ini kurar ve default yapar
 func Setup(cfg config.RedisConfig) {
-	rdb = redis.NewClient(&redis.Options{
+	rdb := redis.NewClient(&redis.Options{
 		Addr:         cfg.Host,
 		Password:     cfg.Password,
 		DB:           cfg.Db,
 		WriteTimeout: cfg.WriteTimeout * time.Second,
 	})
+	rdb.AddHook(tracingHook{})
+	Use(NewRedisBackend(rdb))
+}
+
+// SetupFromConfig config'deki cache.backend'e göre redis veya memory backend'i kurar.
+// memory sadece tek process'te çalışır, geliştirme ve testler için
+func SetupFromConfig(cfg *config.Configuration) error {
+	switch cfg.Cache.Backend {
+	case "", "redis":
+		Setup(cfg.Redis)
+	case "memory":
+		Use(NewMemoryBackend())
+	default:
+		return fmt.Errorf("cache: bilinmeyen backend %q, redis veya memory olmalı", cfg.Cache.Backend)
+	}
+	return nil
 }
 
 func Ping(ctx context.Context) error {
-	return rdb.Ping(ctx).Err()
+	return Default().Ping(ctx)
 }
 
+// Get key yoksa *NotFoundError döner, errors.Is(err, redis.Nil) de doğrudur
 func Get(ctx context.Context, key string) (string, error) {
-	return rdb.Get(ctx, key).Result()
+	data, err := Default().Get(ctx, key)
+	return string(data), err
 }
 
 func Set(ctx context.Context, key string, value []byte, duration time.Duration) error {
-	return rdb.Set(ctx, key, value, duration).Err()
+	return SetWith(ctx, Default(), key, value, duration)
+}
+
+// SetWith Set'in verilen backend'e yazan hali, AppCtx.Cache gibi enjekte edilen backend'ler için.
+// Local veya Broadcast prefix'lerinde yazınca diğer replikaların process içi katmanları da key'i düşer
+func SetWith(ctx context.Context, b Backend, key string, value []byte, duration time.Duration) error {
+	if err := b.Set(ctx, key, value, duration); err != nil {
+		return err
+	}
+	invalidateAfterWrite(ctx, b, key)
+	return nil
+}
+
+// SetNX key yoksa yazar. key zaten varsa false döner
+func SetNX(ctx context.Context, key string, value []byte, duration time.Duration) (bool, error) {
+	return Default().SetNX(ctx, key, value, duration)
 }
+
 func Delete(ctx context.Context, key string) error {
-	return rdb.Del(ctx, key).Err()
+	if err := Default().Delete(ctx, key); err != nil {
+		return err
+	}
+	invalidateAfterWrite(ctx, Default(), key)
+	return nil
 }
 
 func Exist(ctx context.Context, key string) bool {
-	res := rdb.Exists(ctx, key)
-	if res != nil && res.Val() > 0 {
-		return true
+	ok, _ := Default().Exists(ctx, key)
+	return ok
+}
+
+// PoolStats bağlantı havuzu istatistikleri. backend redis değilse nil
+func PoolStats() *redis.PoolStats {
+	rb, ok := Default().(*RedisBackend)
+	if !ok {
+		return nil
+	}
+	return rb.client.PoolStats()
+}
+
+// RunScript lua script çalıştırır. script redis'te cache'lenir, yoksa yüklenir.
+// backend redis değilse ErrScriptNotSupported döner
+func RunScript(ctx context.Context, script *redis.Script, keys []string, args ...interface{}) (interface{}, error) {
+	rb, ok := Default().(*RedisBackend)
+	if !ok {
+		return nil, ErrScriptNotSupported
+	}
+	return script.Run(ctx, rb.client, keys, args...).Result()
+}
+
+type spanKey struct{}
+
+// tracingHook her redis komutu için ctx'teki span'ın altına bir span açar
+type tracingHook struct{}
+
+func (tracingHook) start(ctx context.Context, name string, cmds ...redis.Cmder) context.Context {
+	ctx, span := otel.Tracer("web").Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("db.system", "redis")))
+	if len(cmds) == 1 {
+		span.SetAttributes(attribute.String("db.operation", cmds[0].Name()))
+		if args := cmds[0].Args(); len(args) > 1 {
+			if key, ok := args[1].(string); ok {
+				span.SetAttributes(attribute.String("db.redis.key", key))
+			}
+		}
 	} else {
-		return false
+		span.SetAttributes(attribute.Int("db.redis.num_cmd", len(cmds)))
+	}
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+func (tracingHook) end(ctx context.Context, cmds ...redis.Cmder) {
+	span, ok := ctx.Value(spanKey{}).(trace.Span)
+	if !ok {
+		return
+	}
+	defer span.End()
+	for _, cmd := range cmds {
+		if err := cmd.Err(); err != nil && err != redis.Nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return
+		}
 	}
 }
+
+func (h tracingHook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	return h.start(ctx, "redis."+cmd.Name(), cmd), nil
+}
+
+func (h tracingHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	h.end(ctx, cmd)
+	return nil
+}
+
+func (h tracingHook) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	return h.start(ctx, "redis.pipeline", cmds...), nil
+}
+
+func (h tracingHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	h.end(ctx, cmds...)
+	return nil
+}
@@ -1,11 +1,28 @@
 // custom context kullanmak için. endpoint sayısı artarsa custom .Get .Post vs methodları implemente edilebilir
 // app.Get("/kullanici", CtxWrap(handlers.GetAll))
+// testkit.Kit.Wrap da aynı şekilde context.NewAppCtx kullanır
 func CtxWrap(h func(ctx *context.AppCtx) error) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		return h(&context.AppCtx{Ctx: c, Db: database.DB()})
+		return h(context.NewAppCtx(c, database.DB(), cache.Default()))
 	}
 }
 
+// cache. main'de config okunduktan sonra, cache.backend: memory ise redis gerekmez
+// if err := cache.SetupFromConfig(config.Get()); err != nil {
+// 	log.Fatal(err)
+// }
+
+// tracing. main'de config okunduktan sonra
+// shutdown, err := config.SetupTracing(config.Get().Tracing)
+// defer shutdown(context.Background())
+// database.DB().Use(utils.GormTracing{})
+app.Use(utils.TracingMiddleware())
+
+// metrics. /metrics dışarıya açılmamalı
+// metrics.RegisterDB("main", database.DB())
+app.Use(metrics.Middleware())
+app.Get("/metrics", metrics.Handler())
+
 // limiter middleware
 v1.Use(limiter.New(limiter.Config{
   Next: func(c *fiber.Ctx) bool {
@@ -24,8 +41,9 @@ v1.Use(limiter.New(limiter.Config{
 // app.Get("/health", health)
 func health(c *fiber.Ctx) error {
 	type Status struct {
-		Status  string `json:"status"`
-		Message string `json:"message"`
+		Status   string                `json:"status"`
+		Message  string                `json:"message"`
+		Breakers []utils.BreakerStatus `json:"breakers"`
 	}
 	//var home model.Home
 	//err := database.DB().First(&home).Error
@@ -46,7 +64,15 @@ func health(c *fiber.Ctx) error {
 	//}
 
 	return c.JSON(Status{
-		Status:  "OK",
-		Message: "maşşşallah len",
+		Status:   "OK",
+		Message:  "maşşşallah len",
+		Breakers: utils.BreakerStatuses(),
 	})
 }
+
+// webhook. worker her replikada çalışabilir. teslimatları sadece endpoint'in sahibi (KullaniciID) görebilir
+// endpoint kaydederken url webhook.ValidateURL ile kontrol edilmeli, event'ler webhook.Enqueue(tx, kullaniciID, ...) ile sahibine gider
+// webhook.Migrate(database.DB())
+// go webhook.NewWorker(database.DB(), webhook.WorkerOptions{}).Run(ctx)
+app.Get("/webhooks/:id/deliveries", middleware.Protected(), CtxWrap(webhook.DeliveriesHandler))
+app.Post("/webhooks/deliveries/:id/redeliver", middleware.Protected(), CtxWrap(webhook.RedeliverHandler))
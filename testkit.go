@@ -0,0 +1,302 @@
+package testkit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Secret test tokenlarını imzalamak için kullanılan jwt secret
+const Secret = "testkit-secret"
+
+// Kit handler testleri için hazır fiber app.
+// DB test bitince kendiliğinden kapanır. Cache cache.Use ile process'in default backend'i yapılır,
+// Options.Backend verilmeden kurulan tipli cache'ler de onu kullanır. bu yüzden Kit kullanan
+// testler t.Parallel() ile çalıştırılmamalı
+//
+//	k := testkit.New(t, &model.Kullanici{})
+//	k.Private(fiber.MethodGet, "/profil", handlers.Profil)
+//	k.Get("/profil").As(kullanici).Do().Status(200).Success().Data(&profil)
+type Kit struct {
+	App   *fiber.App
+	DB    *gorm.DB
+	Cache *cache.MemoryBackend
+	t     testing.TB
+}
+
+// New in-memory sqlite ve cache.MemoryBackend üzerinde yeni bir Kit oluşturur.
+// verilen modeller için AutoMigrate çalıştırılır.
+func New(t testing.TB, models ...interface{}) *Kit {
+	t.Helper()
+
+	// global config ve cache backend test bitince eski hallerine döner, process'in config'i yerinde değiştirilmez
+	prevCfg, prevCache := config.Get(), cache.Default()
+	cfg := config.Configuration{}
+	if prevCfg != nil {
+		cfg = *prevCfg
+	}
+	cfg.Server.JwtSecret = Secret
+	config.Set(&cfg)
+
+	mem := cache.NewMemoryBackend()
+	cache.Use(mem)
+	t.Cleanup(func() {
+		cache.Use(prevCache)
+		config.Set(prevCfg)
+	})
+
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", url.PathEscape(t.Name()))
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("testkit: db açılamadı: %v", err)
+	}
+	if len(models) > 0 {
+		if err := db.AutoMigrate(models...); err != nil {
+			t.Fatalf("testkit: migrate: %v", err)
+		}
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	return &Kit{
+		App:   fiber.New(fiber.Config{ErrorHandler: utils.ErrorHandler}),
+		DB:    db,
+		Cache: mem,
+		t:     t,
+	}
+}
+
+// Wrap CtxWrap ile aynı, sadece database.DB() yerine kitin DB'sini ve cache'ini kullanır
+func (k *Kit) Wrap(h func(ctx *context.AppCtx) error) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return h(context.NewAppCtx(c, k.DB, k.Cache))
+	}
+}
+
+// Public login gerektirmeyen bir handler ekler
+func (k *Kit) Public(method, path string, h func(ctx *context.AppCtx) error) {
+	k.App.Add(method, path, k.Wrap(h))
+}
+
+// Private auth middleware arkasında bir handler ekler
+func (k *Kit) Private(method, path string, h func(ctx *context.AppCtx) error) {
+	k.App.Add(method, path, middleware.Protected(), k.Wrap(h))
+}
+
+// Token verilen kullanıcı için imzalı bir jwt üretir
+func (k *Kit) Token(kullanici model.Kullanici) string {
+	k.t.Helper()
+	return Token(k.t, kullanici)
+}
+
+// Token verilen kullanıcı için Secret ile imzalı bir jwt üretir
+func Token(t testing.TB, kullanici model.Kullanici) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"kullaniciID": kullanici.ID,
+		"yetki":       kullanici.Yetki,
+		"eposta":      kullanici.Eposta,
+		"exp":         time.Now().Add(time.Hour).Unix(),
+	})
+	signed, err := token.SignedString([]byte(Secret))
+	if err != nil {
+		t.Fatalf("testkit: token imzalanamadı: %v", err)
+	}
+	return signed
+}
+
+// TokenFor sadece id ve yetki ile token üretir
+func (k *Kit) TokenFor(id int64, yetki model.KullaniciYetki) string {
+	k.t.Helper()
+	return Token(k.t, model.Kullanici{ID: id, Yetki: yetki})
+}
+
+// Request app'e atılacak bir test isteği
+type Request struct {
+	kit    *Kit
+	method string
+	path   string
+	query  url.Values
+	header http.Header
+	body   io.Reader
+}
+
+func (k *Kit) Request(method, path string) *Request {
+	return &Request{kit: k, method: method, path: path, query: url.Values{}, header: http.Header{}}
+}
+
+func (k *Kit) Get(path string) *Request    { return k.Request(fiber.MethodGet, path) }
+func (k *Kit) Post(path string) *Request   { return k.Request(fiber.MethodPost, path) }
+func (k *Kit) Put(path string) *Request    { return k.Request(fiber.MethodPut, path) }
+func (k *Kit) Delete(path string) *Request { return k.Request(fiber.MethodDelete, path) }
+
+// As isteği verilen kullanıcı olarak atar
+func (r *Request) As(kullanici model.Kullanici) *Request {
+	r.kit.t.Helper()
+	return r.Bearer(r.kit.Token(kullanici))
+}
+
+func (r *Request) Bearer(token string) *Request {
+	r.header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+	return r
+}
+
+func (r *Request) Header(key, value string) *Request {
+	r.header.Set(key, value)
+	return r
+}
+
+func (r *Request) Query(key, value string) *Request {
+	r.query.Add(key, value)
+	return r
+}
+
+// JSON body'i json olarak encode eder
+func (r *Request) JSON(body interface{}) *Request {
+	r.kit.t.Helper()
+	data, err := json.Marshal(body)
+	if err != nil {
+		r.kit.t.Fatalf("testkit: body encode edilemedi: %v", err)
+	}
+	r.body = bytes.NewReader(data)
+	r.header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return r
+}
+
+func (r *Request) Body(body io.Reader, contentType string) *Request {
+	r.body = body
+	r.header.Set(fiber.HeaderContentType, contentType)
+	return r
+}
+
+// Do isteği app'e atar
+func (r *Request) Do() *Response {
+	t := r.kit.t
+	t.Helper()
+
+	target := r.path
+	if len(r.query) > 0 {
+		target += "?" + r.query.Encode()
+	}
+	req := httptest.NewRequest(r.method, target, r.body)
+	for k, v := range r.header {
+		req.Header[k] = v
+	}
+
+	res, err := r.kit.App.Test(req, -1)
+	if err != nil {
+		t.Fatalf("testkit: %s %s: %v", r.method, r.path, err)
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatalf("testkit: response okunamadı: %v", err)
+	}
+	return &Response{t: t, Raw: res, Body: body}
+}
+
+// Response ResponseModel üzerinde zincirlenebilir kontroller
+type Response struct {
+	t    testing.TB
+	Raw  *http.Response
+	Body []byte
+
+	model  *context.ResponseModel
+	parsed bool
+}
+
+func (r *Response) Model() *context.ResponseModel {
+	r.t.Helper()
+	if !r.parsed {
+		r.parsed = true
+		r.model = new(context.ResponseModel)
+		if err := json.Unmarshal(r.Body, r.model); err != nil {
+			r.t.Fatalf("testkit: ResponseModel decode edilemedi: %v\nbody: %s", err, r.Body)
+		}
+	}
+	return r.model
+}
+
+func (r *Response) Status(code int) *Response {
+	r.t.Helper()
+	if r.Raw.StatusCode != code {
+		r.t.Errorf("status: beklenen %d, gelen %d\nbody: %s", code, r.Raw.StatusCode, r.Body)
+	}
+	return r
+}
+
+// Success hataVarMi false olmalı
+func (r *Response) Success() *Response {
+	r.t.Helper()
+	if m := r.Model(); m.HataVarMi {
+		r.t.Errorf("hataVarMi: beklenen false, mesaj: %q", m.Message)
+	}
+	return r
+}
+
+// Failure hataVarMi true olmalı
+func (r *Response) Failure() *Response {
+	r.t.Helper()
+	if !r.Model().HataVarMi {
+		r.t.Errorf("hataVarMi: beklenen true\nbody: %s", r.Body)
+	}
+	return r
+}
+
+func (r *Response) Message(msg string) *Response {
+	r.t.Helper()
+	if m := r.Model(); m.Message != msg {
+		r.t.Errorf("message: beklenen %q, gelen %q", msg, m.Message)
+	}
+	return r
+}
+
+func (r *Response) MessageContains(substr string) *Response {
+	r.t.Helper()
+	if m := r.Model(); !strings.Contains(m.Message, substr) {
+		r.t.Errorf("message: %q içermiyor, gelen %q", substr, m.Message)
+	}
+	return r
+}
+
+// Data response içindeki data alanını v'ye decode eder
+func (r *Response) Data(v interface{}) *Response {
+	r.t.Helper()
+	var envelope struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(r.Body, &envelope); err != nil {
+		r.t.Fatalf("testkit: response decode edilemedi: %v\nbody: %s", err, r.Body)
+	}
+	if err := json.Unmarshal(envelope.Data, v); err != nil {
+		r.t.Fatalf("testkit: data decode edilemedi: %v\ndata: %s", err, envelope.Data)
+	}
+	return r
+}
+
+// TotalRecords SuccessAndTotalRecordsResponse ile dönen sayıyı kontrol eder
+func (r *Response) TotalRecords(n int64) *Response {
+	r.t.Helper()
+	var data struct {
+		TotalRecords int64 `json:"totalRecords"`
+	}
+	r.Data(&data)
+	if data.TotalRecords != n {
+		r.t.Errorf("totalRecords: beklenen %d, gelen %d", n, data.TotalRecords)
+	}
+	return r
+}
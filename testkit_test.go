@@ -0,0 +1,51 @@
+package testkit
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"testing"
+)
+
+func TestKitPrivateRequiresToken(t *testing.T) {
+	k := New(t)
+	k.Private(fiber.MethodGet, "/profil", func(c *context.AppCtx) error {
+		return c.SuccessResponse(c.GetUser())
+	})
+
+	var kullanici model.Kullanici
+	k.Get("/profil").As(model.Kullanici{ID: 7, Eposta: "a@b.com"}).Do().Status(200).Success().Data(&kullanici)
+	if kullanici.ID != 7 || kullanici.Eposta != "a@b.com" {
+		t.Fatalf("%+v", kullanici)
+	}
+	k.Get("/profil").Do().Status(401).Failure()
+}
+
+func TestKitCacheIsIsolated(t *testing.T) {
+	k := New(t)
+	k.Public(fiber.MethodPost, "/sayac", func(c *context.AppCtx) error {
+		c.SetToCache("sayac", 1)
+		return c.SuccessResponse(nil)
+	})
+
+	k.Post("/sayac").Do().Status(200)
+	if ok, _ := k.Cache.Exists(t.Context(), "sayac"); !ok {
+		t.Fatal("handler kitin cache'ine yazmalı")
+	}
+}
+
+func TestKitRestoresGlobals(t *testing.T) {
+	prevCfg, prevCache := config.Get(), cache.Default()
+
+	t.Run("kit", func(t *testing.T) {
+		k := New(t)
+		if cache.Default() != cache.Backend(k.Cache) || config.Get().Server.JwtSecret != Secret {
+			t.Fatal("kit global config ve cache'i ayarlamalı")
+		}
+	})
+
+	if config.Get() != prevCfg || cache.Default() != prevCache {
+		t.Fatal("test bitince eski config ve cache geri gelmeli")
+	}
+	if prevCfg != nil && prevCfg.Server.JwtSecret == Secret {
+		t.Fatal("process'in config'i yerinde değiştirildi")
+	}
+}
@@ -0,0 +1,480 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	StatusPending   = "pending"
+	StatusDelivered = "delivered"
+	// StatusDead deneme hakkı biten teslimatlar. Redeliver ile tekrar kuyruğa alınabilir
+	StatusDead = "dead"
+)
+
+// ErrDeliveryNotFound Redeliver'a olmayan bir teslimat verildiğinde
+var ErrDeliveryNotFound = errors.New("webhook teslimatı bulunamadı")
+
+// Endpoint müşterinin event'leri almak istediği adres.
+// Events virgülle ayrılmış event isimleri, "*" hepsi demek.
+// KullaniciID endpoint'in sahibi, teslimatları sadece o görebilir
+type Endpoint struct {
+	ID          int64     `json:"id" gorm:"primaryKey"`
+	KullaniciID int64     `json:"kullaniciId" gorm:"not null;default:0;index"`
+	URL         string    `json:"url" gorm:"not null"`
+	Secret      string    `json:"-" gorm:"not null"`
+	Events      string    `json:"events" gorm:"not null;default:'*'"`
+	Active      bool      `json:"active" gorm:"not null;default:true"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+func (e Endpoint) subscribed(event string) bool {
+	for _, ev := range strings.Split(e.Events, ",") {
+		ev = strings.TrimSpace(ev)
+		if ev == "*" || ev == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Delivery bir event'in bir endpoint'e teslimatı
+type Delivery struct {
+	ID            int64           `json:"id" gorm:"primaryKey"`
+	EndpointID    int64           `json:"endpointId" gorm:"not null;index"`
+	Event         string          `json:"event" gorm:"not null"`
+	Payload       json.RawMessage `json:"payload" gorm:"type:text;not null"`
+	Status        string          `json:"status" gorm:"not null;index:idx_webhook_due,priority:1"`
+	Attempts      int             `json:"attempts" gorm:"not null;default:0"`
+	NextAttemptAt time.Time       `json:"nextAttemptAt" gorm:"index:idx_webhook_due,priority:2"`
+	LockedUntil   *time.Time      `json:"-"`
+	LockedBy      *string         `json:"-" gorm:"size:32"`
+	LastError     string          `json:"lastError"`
+	DeliveredAt   *time.Time      `json:"deliveredAt"`
+	CreatedAt     time.Time       `json:"createdAt"`
+	UpdatedAt     time.Time       `json:"updatedAt"`
+	Logs          []Attempt       `json:"logs,omitempty" gorm:"foreignKey:DeliveryID"`
+}
+
+// Attempt her teslimat denemesinin kaydı
+type Attempt struct {
+	ID           int64     `json:"id" gorm:"primaryKey"`
+	DeliveryID   int64     `json:"deliveryId" gorm:"not null;index"`
+	EndpointID   int64     `json:"endpointId" gorm:"not null;index"`
+	StatusCode   int       `json:"statusCode"`
+	Error        string    `json:"error"`
+	ResponseBody string    `json:"responseBody"`
+	DurationMs   int64     `json:"durationMs"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+func (Endpoint) TableName() string { return "webhook_endpoints" }
+
+func (Delivery) TableName() string { return "webhook_deliveries" }
+
+func (Attempt) TableName() string { return "webhook_attempts" }
+
+func Migrate(db *gorm.DB) error {
+	return db.AutoMigrate(&Endpoint{}, &Delivery{}, &Attempt{})
+}
+
+// Enqueue event'i kullanıcının abone olan aktif endpoint'leri için kuyruğa ekler. kullaniciID
+// event'in ait olduğu kullanıcı, başka kullanıcıların endpoint'lerine gönderilmez.
+// handler'ın transaction'ı ile çağrılırsa event sadece transaction commit olursa gönderilir
+//
+//	err := c.Db.Transaction(func(tx *gorm.DB) error {
+//		if err := tx.Create(&siparis).Error; err != nil {
+//			return err
+//		}
+//		return webhook.Enqueue(tx, siparis.KullaniciID, "siparis.olustu", siparis)
+//	})
+func Enqueue(db *gorm.DB, kullaniciID int64, event string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	var endpoints []Endpoint
+	if err := db.Where("kullanici_id = ? AND active = ?", kullaniciID, true).Find(&endpoints).Error; err != nil {
+		return err
+	}
+	now := time.Now()
+	var deliveries []Delivery
+	for _, e := range endpoints {
+		if !e.subscribed(event) {
+			continue
+		}
+		deliveries = append(deliveries, Delivery{
+			EndpointID:    e.ID,
+			Event:         event,
+			Payload:       data,
+			Status:        StatusPending,
+			NextAttemptAt: now,
+		})
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return db.Create(&deliveries).Error
+}
+
+// ErrForbidden teslimat veya endpoint isteyen kullanıcıya ait değilse
+var ErrForbidden = errors.New("webhook bu kullanıcıya ait değil")
+
+// EndpointOwnedBy endpoint'in kullanıcıya ait olup olmadığını kontrol eder.
+// başkasının endpoint'i de yok sayılır ki id'ler denenerek bulunamasın
+func EndpointOwnedBy(db *gorm.DB, endpointID, kullaniciID int64) error {
+	// sahibi olmayan eski endpoint'ler kimseye açılmaz
+	if kullaniciID == 0 {
+		return ErrForbidden
+	}
+	var count int64
+	err := db.Model(&Endpoint{}).
+		Where("id = ? AND kullanici_id = ?", endpointID, kullaniciID).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// DeliveryOwnedBy teslimatın endpoint'inin kullanıcıya ait olup olmadığını kontrol eder
+func DeliveryOwnedBy(db *gorm.DB, deliveryID, kullaniciID int64) error {
+	// sahibi olmayan eski endpoint'ler kimseye açılmaz
+	if kullaniciID == 0 {
+		return ErrForbidden
+	}
+	var count int64
+	err := db.Model(&Delivery{}).
+		Joins("JOIN webhook_endpoints ON webhook_endpoints.id = webhook_deliveries.endpoint_id").
+		Where("webhook_deliveries.id = ? AND webhook_endpoints.kullanici_id = ?", deliveryID, kullaniciID).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// Redeliver teslimatı baştan kuyruğa alır, deneme sayısı sıfırlanır. eski deneme kayıtları durur
+func Redeliver(db *gorm.DB, id int64) (*Delivery, error) {
+	var d Delivery
+	if err := db.First(&d, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDeliveryNotFound
+		}
+		return nil, err
+	}
+	d.Status = StatusPending
+	d.Attempts = 0
+	d.NextAttemptAt = time.Now()
+	err := db.Model(&d).Select("status", "attempts", "next_attempt_at").Updates(&d).Error
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// WorkerOptions zero değerler default'a döner
+type WorkerOptions struct {
+	// Client teslimatların gideceği client. nil ise paylaşılan client'lardan ayrı, iç ağ
+	// adreslerine bağlanmayan bir client kurulur
+	Client *utils.Client
+	// AllowPrivateNetworks loopback ve özel ağ adreslerine teslimata izin verir. test ve geliştirme için
+	AllowPrivateNetworks bool
+	// MaxAttempts bu kadar başarısız denemeden sonra teslimat dead olur, default 8
+	MaxAttempts int
+	// BaseDelay ilk tekrar beklemesi, her denemede iki katına çıkar. default 30sn
+	BaseDelay time.Duration
+	// MaxDelay default 6 saat
+	MaxDelay time.Duration
+	// Timeout tek bir deneme için süre, default 10sn
+	Timeout time.Duration
+	// PollInterval kuyruğa bakma aralığı, default 5sn
+	PollInterval time.Duration
+	// BatchSize bir seferde alınan teslimat, default 50
+	BatchSize int
+	// Concurrency aynı anda gönderilen teslimat, default 4
+	Concurrency int
+	// Signing imza header isimleri, secret endpoint'ten alınır
+	Signing utils.SigningOptions
+}
+
+func (o WorkerOptions) withDefaults() WorkerOptions {
+	if o.Client == nil {
+		o.Client = &utils.Client{Name: "webhook", HTTP: newClient(o.AllowPrivateNetworks)}
+	}
+	if o.MaxAttempts <= 0 {
+		o.MaxAttempts = 8
+	}
+	if o.BaseDelay <= 0 {
+		o.BaseDelay = 30 * time.Second
+	}
+	if o.MaxDelay <= 0 {
+		o.MaxDelay = 6 * time.Hour
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = 10 * time.Second
+	}
+	if o.PollInterval <= 0 {
+		o.PollInterval = 5 * time.Second
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = 50
+	}
+	if o.Concurrency <= 0 {
+		o.Concurrency = 4
+	}
+	o.Signing = o.Signing.WithDefaults()
+	return o
+}
+
+// Worker kuyruktaki teslimatları gönderir. birden fazla replikada çalışabilir,
+// her teslimat kilitlenerek alınır ve göndermeden hemen önce kilit tekrar kontrol
+// edildiği için iki kere gönderilmez
+//
+//	w := webhook.NewWorker(database.DB(), webhook.WorkerOptions{})
+//	go w.Run(ctx)
+type Worker struct {
+	db   *gorm.DB
+	opts WorkerOptions
+	log  *zap.Logger
+}
+
+func NewWorker(db *gorm.DB, opts WorkerOptions) *Worker {
+	return &Worker{db: db, opts: opts.withDefaults(), log: config.Logger("webhook")}
+}
+
+// Run ctx bitene kadar kuyruğu işler
+func (w *Worker) Run(ctx context.Context) error {
+	ticker := time.NewTicker(w.opts.PollInterval)
+	defer ticker.Stop()
+	for {
+		// batch doluysa beklemeden devam et
+		for {
+			n, err := w.Process(ctx)
+			if err != nil {
+				w.log.Error("webhook kuyruğu okunamadı", zap.Error(err))
+				break
+			}
+			if n < w.opts.BatchSize || ctx.Err() != nil {
+				break
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Process zamanı gelmiş teslimatlardan bir batch gönderir, gönderilen sayısını döner
+func (w *Worker) Process(ctx context.Context) (int, error) {
+	deliveries, token, err := w.claim(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	sem := make(chan struct{}, w.opts.Concurrency)
+	var wg sync.WaitGroup
+	for i := range deliveries {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(d *Delivery) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			w.deliver(ctx, d, token)
+		}(&deliveries[i])
+	}
+	wg.Wait()
+	return len(deliveries), nil
+}
+
+// claim zamanı gelmiş teslimatları kilitler. başka bir worker'ın aynı anda kilitlediği satır atlanır.
+// kilit tüm batch gönderilene kadar yetecek kadar tutulur, dönen token lease'i yenilerken kullanılır
+func (w *Worker) claim(ctx context.Context) ([]Delivery, string, error) {
+	db := w.db.WithContext(ctx)
+	now := time.Now()
+	var due []Delivery
+	err := db.Where("status = ? AND next_attempt_at <= ? AND (locked_until IS NULL OR locked_until < ?)", StatusPending, now, now).
+		Order("next_attempt_at").
+		Limit(w.opts.BatchSize).
+		Find(&due).Error
+	if err != nil {
+		return nil, "", err
+	}
+
+	// batch Concurrency'lik dalgalar halinde gider, son dalga ve kayıt için bir Timeout pay
+	waves := (len(due) + w.opts.Concurrency - 1) / w.opts.Concurrency
+	lockedUntil := now.Add(time.Duration(waves+1) * w.opts.Timeout)
+	token := strconv.FormatUint(rand.Uint64(), 36)
+	claimed := due[:0]
+	for _, d := range due {
+		res := db.Model(&Delivery{}).
+			Where("id = ? AND (locked_until IS NULL OR locked_until < ?)", d.ID, now).
+			Updates(map[string]interface{}{"locked_until": lockedUntil, "locked_by": token})
+		if res.Error != nil {
+			return nil, "", res.Error
+		}
+		if res.RowsAffected == 1 {
+			claimed = append(claimed, d)
+		}
+	}
+	return claimed, token, nil
+}
+
+// renew göndermeden hemen önce kilidin hâlâ bizde olduğunu kontrol edip bir deneme süresi uzatır.
+// kilit süresi dolup başka bir worker teslimatı almışsa veya teslimat bu arada
+// değişmişse (Redeliver, başka bir deneme) false döner ve teslimat gönderilmez
+func (w *Worker) renew(ctx context.Context, d *Delivery, token string) (bool, error) {
+	res := w.db.WithContext(ctx).Model(&Delivery{}).
+		Where("id = ? AND locked_by = ? AND attempts = ? AND status = ?", d.ID, token, d.Attempts, StatusPending).
+		Update("locked_until", time.Now().Add(2*w.opts.Timeout))
+	return res.RowsAffected == 1, res.Error
+}
+
+// envelope endpoint'e giden body
+type envelope struct {
+	ID        int64           `json:"id"`
+	Event     string          `json:"event"`
+	CreatedAt time.Time       `json:"createdAt"`
+	Data      json.RawMessage `json:"data"`
+}
+
+func (w *Worker) deliver(ctx context.Context, d *Delivery, token string) {
+	owned, err := w.renew(ctx, d, token)
+	if err != nil {
+		w.log.Error("webhook kilidi yenilenemedi", zap.Int64("delivery", d.ID), zap.Error(err))
+		return
+	}
+	if !owned {
+		return
+	}
+
+	var endpoint Endpoint
+	if err := w.db.WithContext(ctx).First(&endpoint, d.EndpointID).Error; err != nil {
+		w.finish(ctx, d, Attempt{Error: "endpoint bulunamadı: " + err.Error()}, false)
+		return
+	}
+	// kuyruğa alındıktan sonra kapatılan endpoint'e gönderilmez, Redeliver ile tekrar alınabilir
+	if !endpoint.Active {
+		w.drop(ctx, d, "endpoint pasif")
+		return
+	}
+	if !w.opts.AllowPrivateNetworks {
+		if err := ValidateURL(ctx, endpoint.URL); errors.Is(err, ErrBlockedAddress) {
+			w.drop(ctx, d, err.Error())
+			return
+		}
+	}
+
+	body, _ := json.Marshal(envelope{ID: d.ID, Event: d.Event, CreatedAt: d.CreatedAt, Data: d.Payload})
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+
+	// tekrar denemeyi kuyruk yapıyor, client'ın kendi retry'ı kapatılır
+	reqCtx := utils.WithRetryPolicy(ctx, utils.NoRetry)
+	start := time.Now()
+	res, err := utils.NewRequest(http.MethodPost, endpoint.URL).
+		Client(w.opts.Client.HTTP).
+		Timeout(w.opts.Timeout).
+		Header("X-Webhook-Event", d.Event).
+		Header("X-Webhook-Delivery", strconv.FormatInt(d.ID, 10)).
+		Header(w.opts.Signing.TimestampHeader, ts).
+		Header(w.opts.Signing.SignatureHeader, utils.Sign(endpoint.Secret, ts, body)).
+		Body(bytes.NewReader(body), "application/json; charset=utf-8").
+		Do(reqCtx)
+
+	attempt := Attempt{DeliveryID: d.ID, EndpointID: endpoint.ID, DurationMs: time.Since(start).Milliseconds()}
+	if err != nil {
+		attempt.Error = err.Error()
+		w.finish(ctx, d, attempt, false)
+		return
+	}
+	defer res.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+	attempt.StatusCode = res.StatusCode
+	attempt.ResponseBody = string(respBody)
+	ok := res.StatusCode >= 200 && res.StatusCode <= 299
+	if !ok {
+		attempt.Error = res.Status
+	}
+	w.finish(ctx, d, attempt, ok)
+}
+
+// finish denemeyi kaydeder ve teslimatın durumunu günceller
+func (w *Worker) finish(ctx context.Context, d *Delivery, attempt Attempt, ok bool) {
+	now := time.Now()
+	attempt.DeliveryID = d.ID
+	if attempt.EndpointID == 0 {
+		attempt.EndpointID = d.EndpointID
+	}
+	d.Attempts++
+	updates := map[string]interface{}{
+		"attempts":     d.Attempts,
+		"locked_until": nil,
+		"locked_by":    nil,
+		"last_error":   attempt.Error,
+	}
+	switch {
+	case ok:
+		d.Status = StatusDelivered
+		updates["delivered_at"] = now
+	case d.Attempts >= w.opts.MaxAttempts:
+		d.Status = StatusDead
+		w.log.Warn("webhook teslim edilemedi", zap.Int64("delivery", d.ID), zap.Int64("endpoint", d.EndpointID), zap.String("error", attempt.Error))
+	default:
+		updates["next_attempt_at"] = now.Add(w.backoff(d.Attempts))
+	}
+	updates["status"] = d.Status
+
+	err := w.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&attempt).Error; err != nil {
+			return err
+		}
+		return tx.Model(&Delivery{}).Where("id = ?", d.ID).Updates(updates).Error
+	})
+	if err != nil {
+		w.log.Error("webhook denemesi kaydedilemedi", zap.Int64("delivery", d.ID), zap.Error(err))
+	}
+}
+
+// drop teslimatı deneme saymadan dead yapar
+func (w *Worker) drop(ctx context.Context, d *Delivery, reason string) {
+	d.Status = StatusDead
+	err := w.db.WithContext(ctx).Model(&Delivery{}).Where("id = ?", d.ID).Updates(map[string]interface{}{
+		"status":       StatusDead,
+		"locked_until": nil,
+		"locked_by":    nil,
+		"last_error":   reason,
+	}).Error
+	if err != nil {
+		w.log.Error("webhook teslimatı güncellenemedi", zap.Int64("delivery", d.ID), zap.Error(err))
+	}
+}
+
+// backoff attempt. başarısız denemeden sonra beklenecek süre, yarısı sabit yarısı rastgele
+func (w *Worker) backoff(attempt int) time.Duration {
+	d := w.opts.BaseDelay << uint(attempt-1)
+	if d <= 0 || d > w.opts.MaxDelay {
+		d = w.opts.MaxDelay
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
@@ -0,0 +1,92 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// ErrBlockedAddress endpoint adresi loopback, özel ağ, link-local gibi iç bir adrese çıkıyorsa.
+// endpoint url'lerini müşteri verdiği için worker'ın iç servislere istek atmasına izin verilmez
+var ErrBlockedAddress = errors.New("webhook adresi iç ağa işaret ediyor")
+
+// carrier grade nat, IsPrivate kapsamıyor
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+func blockedAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() || sharedAddressSpace.Contains(addr)
+}
+
+// ValidateURL endpoint kaydedilirken çağrılmalı. http/https olmayan ve host'u iç bir adrese
+// çözülen url'lerde hata döner. dns sonradan değişebileceği için worker bağlanırken tekrar kontrol eder
+func ValidateURL(ctx context.Context, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("webhook adresi http veya https olmalı: %q", u.Scheme)
+	}
+	host := u.Hostname()
+	if host == "" {
+		return errors.New("webhook adresinde host yok")
+	}
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if blockedAddr(addr) {
+			return fmt.Errorf("%w: %s", ErrBlockedAddress, host)
+		}
+		return nil
+	}
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return err
+	}
+	for _, addr := range addrs {
+		if blockedAddr(addr) {
+			return fmt.Errorf("%w: %s (%s)", ErrBlockedAddress, host, addr)
+		}
+	}
+	return nil
+}
+
+// newClient teslimatlar için interceptor'suz, proxy'siz bir client. paylaşılan client'lar
+// header, token veya imza ekleyebildiği için kullanılmaz. allowPrivate false ise bağlanılan
+// her adres kontrol edilir, dns rebinding ve iç adrese redirect de böylece engellenir.
+// redirect'ler takip edilmez, 3xx başarısız deneme sayılır
+func newClient(allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: 10 * time.Second, KeepAlive: 30 * time.Second}
+	if !allowPrivate {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			ap, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if blockedAddr(ap.Addr()) {
+				return fmt.Errorf("%w: %s", ErrBlockedAddress, ap.Addr())
+			}
+			return nil
+		}
+	}
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy:                 nil,
+			DialContext:           dialer.DialContext,
+			MaxIdleConns:          100,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ExpectContinueTimeout: time.Second,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
@@ -0,0 +1,71 @@
+package webhook
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"strconv"
+)
+
+// DeliveriesHandler bir endpoint'in teslimatlarını deneme kayıtlarıyla listeler.
+// sadece endpoint'in sahibi görebilir, başkasının endpoint'i için 404 döner
+//
+//	app.Get("/webhooks/:id/deliveries", middleware.Protected(), CtxWrap(webhook.DeliveriesHandler))
+func DeliveriesHandler(c *context.AppCtx) error {
+	endpointID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return utils.ErrorBadRequest("geçersiz endpoint id")
+	}
+	if err := EndpointOwnedBy(c.Db, endpointID, c.GetUserID()); err != nil {
+		if errors.Is(err, ErrForbidden) {
+			return c.NotFoundResponse()
+		}
+		return utils.ErrorVeritabani(err, "webhook endpoint'i okunamadı")
+	}
+	p, err := c.GetPaginationModel()
+	if err != nil {
+		return utils.ErrorBadRequest(err.Error())
+	}
+
+	query := c.Db.Model(&Delivery{}).Where("endpoint_id = ?", endpointID)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return utils.ErrorVeritabani(err, "webhook teslimatları sayılamadı")
+	}
+	var deliveries []Delivery
+	err = query.Preload("Logs", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Order("id desc").
+		Offset(p.Offset).
+		Limit(p.PerPage).
+		Find(&deliveries).Error
+	if err != nil {
+		return utils.ErrorVeritabani(err, "webhook teslimatları okunamadı")
+	}
+	return c.SuccessAndTotalRecordsResponse(deliveries, total)
+}
+
+// RedeliverHandler teslimatı tekrar kuyruğa alır. sadece endpoint'in sahibi yapabilir
+//
+//	app.Post("/webhooks/deliveries/:id/redeliver", middleware.Protected(), CtxWrap(webhook.RedeliverHandler))
+func RedeliverHandler(c *context.AppCtx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return utils.ErrorBadRequest("geçersiz teslimat id")
+	}
+	if err := DeliveryOwnedBy(c.Db, id, c.GetUserID()); err != nil {
+		if errors.Is(err, ErrForbidden) {
+			return c.NotFoundResponse()
+		}
+		return utils.ErrorVeritabani(err, "webhook teslimatı okunamadı")
+	}
+	d, err := Redeliver(c.Db, id)
+	if errors.Is(err, ErrDeliveryNotFound) {
+		return c.NotFoundResponse()
+	}
+	if err != nil {
+		return utils.ErrorVeritabani(err, "webhook tekrar kuyruğa alınamadı")
+	}
+	return c.SuccessResponse(d)
+}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"strconv"
+	"time"
+)
+
+// VerifySignature partner'dan gelen imzalı istekleri doğrular, bkz. utils.SignRequest.
+// timestamp pencere dışındaysa, imza tutmuyorsa veya aynı imza daha önce geldiyse 401 döner.
+// handler hata dönerse imza silinir, karşı tarafın tekrar denemesi kabul edilir
+//
+//	app.Post("/webhook/partner", middleware.VerifySignature(utils.SigningOptions{Secret: secret}), CtxWrap(handlers.PartnerWebhook))
+func VerifySignature(opts utils.SigningOptions) fiber.Handler {
+	opts = opts.WithDefaults()
+	return func(c *fiber.Ctx) error {
+		ts := c.Get(opts.TimestampHeader)
+		signature := c.Get(opts.SignatureHeader)
+		if ts == "" || signature == "" {
+			return utils.ErrorNotLogged("imza bulunamadı")
+		}
+
+		unix, err := strconv.ParseInt(ts, 10, 64)
+		if err != nil {
+			return utils.ErrorNotLogged("imza zamanı hatalı")
+		}
+		diff := time.Since(time.Unix(unix, 0))
+		if diff > opts.Window || diff < -opts.Window {
+			return utils.ErrorNotLogged("imza zaman aşımına uğradı")
+		}
+
+		if !utils.VerifySignature(opts.Secret, ts, c.Body(), signature) {
+			return utils.ErrorNotLogged("imza geçersiz")
+		}
+
+		// pencere dışındaki istekler zaten reddedildiği için key'in 2 pencere kadar tutulması yeterli
+		key := "webhook:replay:" + signature
+		ok, err := cache.SetNX(c.UserContext(), key, []byte(ts), 2*opts.Window)
+		if err != nil {
+			return utils.ErrorInternalError(err, "imza tekrar kontrolü yapılamadı")
+		}
+		if !ok {
+			return utils.ErrorNotLogged("bu istek daha önce alındı")
+		}
+
+		err = c.Next()
+		if err != nil || c.Response().StatusCode() >= fiber.StatusInternalServerError {
+			if delErr := cache.Delete(c.UserContext(), key); delErr != nil {
+				config.Logger(c.Get("requestid")).Warn("webhook imzası silinemedi", zap.Error(delErr))
+			}
+		}
+		return err
+	}
+}
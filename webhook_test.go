@@ -0,0 +1,173 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", strings.ReplaceAll(t.Name(), "/", "_"))
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+func TestEnqueueOnlyOwnersSubscribedEndpoints(t *testing.T) {
+	db := testDB(t)
+	db.Create(&Endpoint{KullaniciID: 1, URL: "https://a.test", Secret: "s", Events: "siparis.olustu", Active: true})
+	db.Create(&Endpoint{KullaniciID: 1, URL: "https://b.test", Secret: "s", Events: "fatura.kesildi", Active: true})
+	pasif := Endpoint{KullaniciID: 1, URL: "https://c.test", Secret: "s", Events: "*"}
+	db.Create(&pasif)
+	// default:true yüzünden false Create'te yazılmıyor
+	db.Model(&pasif).Update("active", false)
+	db.Create(&Endpoint{KullaniciID: 2, URL: "https://d.test", Secret: "s", Events: "*", Active: true})
+
+	if err := Enqueue(db, 1, "siparis.olustu", map[string]int{"id": 5}); err != nil {
+		t.Fatal(err)
+	}
+	var deliveries []Delivery
+	db.Find(&deliveries)
+	if len(deliveries) != 1 || deliveries[0].EndpointID != 1 || deliveries[0].Status != StatusPending {
+		t.Fatalf("%+v", deliveries)
+	}
+}
+
+func TestOwnedBy(t *testing.T) {
+	db := testDB(t)
+	e := Endpoint{KullaniciID: 7, URL: "https://a.test", Secret: "s", Events: "*", Active: true}
+	db.Create(&e)
+	Enqueue(db, 7, "a", 1)
+	var d Delivery
+	db.First(&d)
+
+	if err := EndpointOwnedBy(db, e.ID, 7); err != nil {
+		t.Fatal(err)
+	}
+	if err := DeliveryOwnedBy(db, d.ID, 7); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []int64{0, 8} {
+		if EndpointOwnedBy(db, e.ID, id) != ErrForbidden || DeliveryOwnedBy(db, d.ID, id) != ErrForbidden {
+			t.Fatalf("%d kullanıcısı erişememeli", id)
+		}
+	}
+}
+
+func TestWorkerRetriesThenDelivers(t *testing.T) {
+	db := testDB(t)
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !utils.VerifySignature("sec", r.Header.Get("X-Signature-Timestamp"), body, r.Header.Get("X-Signature")) {
+			t.Error("imza doğrulanamadı")
+		}
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte("tamam"))
+	}))
+	defer srv.Close()
+	db.Create(&Endpoint{KullaniciID: 1, URL: srv.URL, Secret: "sec", Events: "*", Active: true})
+	Enqueue(db, 1, "a", 1)
+
+	w := NewWorker(db, WorkerOptions{AllowPrivateNetworks: true, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond})
+	ctx := context.Background()
+	w.Process(ctx)
+	time.Sleep(5 * time.Millisecond)
+	w.Process(ctx)
+
+	var d Delivery
+	db.Preload("Logs").First(&d)
+	if d.Status != StatusDelivered || d.Attempts != 2 || len(d.Logs) != 2 || d.LockedBy != nil {
+		t.Fatalf("%+v", d)
+	}
+}
+
+func TestClaimHoldsLeaseForBatch(t *testing.T) {
+	db := testDB(t)
+	db.Create(&Endpoint{KullaniciID: 1, URL: "https://a.test", Secret: "s", Events: "*", Active: true})
+	for i := 0; i < 4; i++ {
+		Enqueue(db, 1, "a", i)
+	}
+	w := NewWorker(db, WorkerOptions{Timeout: time.Second, Concurrency: 2})
+	claimed, token, err := w.claim(context.Background())
+	if err != nil || len(claimed) != 4 || token == "" {
+		t.Fatal(claimed, token, err)
+	}
+	// 2 dalga + 1 timeout pay
+	var d Delivery
+	db.First(&d, claimed[3].ID)
+	if left := time.Until(*d.LockedUntil); left < 2500*time.Millisecond {
+		t.Fatalf("kilit son dalgaya yetmez: %v", left)
+	}
+	if again, _, _ := w.claim(context.Background()); len(again) != 0 {
+		t.Fatalf("kilitli teslimatlar tekrar alındı: %d", len(again))
+	}
+
+	// kilit başka bir worker'a geçtiyse gönderilmez
+	db.Model(&Delivery{}).Where("id = ?", d.ID).Update("locked_by", "baska")
+	if owned, err := w.renew(context.Background(), &d, token); err != nil || owned {
+		t.Fatalf("owned=%v err=%v", owned, err)
+	}
+}
+
+func TestWorkerRefusesInternalAddresses(t *testing.T) {
+	db := testDB(t)
+	var hit int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hit, 1)
+	}))
+	defer srv.Close()
+	db.Create(&Endpoint{KullaniciID: 1, URL: srv.URL, Secret: "s", Events: "*", Active: true})
+	Enqueue(db, 1, "a", 1)
+
+	NewWorker(db, WorkerOptions{}).Process(context.Background())
+	var d Delivery
+	db.First(&d)
+	if atomic.LoadInt32(&hit) != 0 || d.Status != StatusDead {
+		t.Fatalf("iç adrese gönderildi: %+v", d)
+	}
+	if _, err := newClient(false).Get(srv.URL); !errors.Is(err, ErrBlockedAddress) {
+		t.Fatalf("client iç adrese bağlanmamalı: %v", err)
+	}
+}
+
+func TestValidateURL(t *testing.T) {
+	ctx := context.Background()
+	blocked := []string{
+		"http://127.0.0.1/x", "http://10.1.2.3/", "http://192.168.1.1/", "http://[::1]/",
+		"http://169.254.169.254/latest/meta-data", "http://100.64.0.1/", "http://[::ffff:10.0.0.1]/",
+		"http://localhost:8080/", "ftp://ornek.com/", "http:///yol",
+	}
+	for _, u := range blocked {
+		if ValidateURL(ctx, u) == nil {
+			t.Errorf("%s kabul edilmemeli", u)
+		}
+	}
+	if err := ValidateURL(ctx, "https://93.184.216.34/hook"); err != nil {
+		t.Fatal(err)
+	}
+}